@@ -0,0 +1,159 @@
+package bodyrest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// AuditEntry describes a request handled by HandleTo.
+type AuditEntry struct {
+	Method string
+	// Route is the matched route pattern, or the path when there is none.
+	Route string
+	// Principal identifies the caller: the bound claims with sensitive fields
+	// redacted, BasicCredentials without the password or the fingerprint of
+	// an APIKey, see KeyFingerprint.
+	Principal any
+	// Request is the bound struct parameter with sensitive fields redacted,
+	// see Redact.
+	Request  any
+	Status   int
+	Duration time.Duration
+}
+
+// AuditSink receives an entry for every request handled by HandleTo, after
+// the response has been written.
+type AuditSink interface {
+	Audit(ctx context.Context, entry AuditEntry)
+}
+
+// AuditSinkFunc adapts a function to AuditSink.
+type AuditSinkFunc func(ctx context.Context, entry AuditEntry)
+
+func (f AuditSinkFunc) Audit(ctx context.Context, entry AuditEntry) {
+	f(ctx, entry)
+}
+
+var auditSink AuditSink
+
+// SetAuditSink sets the sink receiving audit entries. A nil sink disables
+// auditing.
+func SetAuditSink(sink AuditSink) {
+	auditSink = sink
+}
+
+// auditRecord collects what is audited about a single request. A nil record
+// audits nothing.
+type auditRecord struct {
+	sink  AuditSink
+	w     *statusRecorder
+	r     *http.Request
+	start time.Time
+	args  []reflect.Value
+}
+
+// startAudit returns the writer to respond with and the audit record of r,
+// or w itself and nil when auditing is disabled.
+func startAudit(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *auditRecord) {
+	sink := auditSink
+	if sink == nil {
+		return w, nil
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	return rec, &auditRecord{sink: sink, w: rec, r: r, start: time.Now()}
+}
+
+func (a *auditRecord) setArgs(args []reflect.Value) {
+	if a != nil {
+		a.args = args
+	}
+}
+
+func (a *auditRecord) finish(plan *bindPlan) {
+	if a == nil {
+		return
+	}
+
+	principal, req, _ := boundRoles(plan, a.args)
+	principal = auditedPrincipal(principal)
+	if req != nil {
+		req = Redact(req)
+	}
+
+	a.sink.Audit(a.r.Context(), AuditEntry{
+		Method:    a.r.Method,
+		Route:     routePattern(a.r),
+		Principal: principal,
+		Request:   req,
+		Status:    a.w.statusCode(),
+		Duration:  time.Since(a.start),
+	})
+}
+
+// auditedPrincipal reduces principal to what identifies the caller, so
+// secrets never reach the audit sink.
+func auditedPrincipal(principal any) any {
+	switch p := principal.(type) {
+	case nil:
+		return nil
+	case BasicCredentials:
+		return BasicCredentials{Username: p.Username}
+	case APIKey:
+		return KeyFingerprint(p)
+	}
+
+	if typeHasTag(reflect.TypeOf(principal), "sensitive") {
+		return Redact(principal)
+	}
+
+	return principal
+}
+
+// KeyFingerprint identifies an API key without revealing it: the first 16
+// hex digits of its SHA-256.
+func KeyFingerprint(key APIKey) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// statusRecorder remembers the status written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusRecorder) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,129 @@
+package bodyrest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAuditSink(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		body     string
+		expected AuditEntry
+	}{
+		{
+			name: "handled request",
+			path: "/accounts/42/login",
+			body: `{"username":"john","password":"secret"}`,
+			expected: AuditEntry{
+				Method:    "POST",
+				Route:     "/accounts/{id}/login",
+				Principal: KeyFingerprint("key-1"),
+				Request:   map[string]any{"username": "john", "password": redactedValue, "card": nil},
+				Status:    http.StatusNoContent,
+			},
+		},
+		{
+			name: "rejected request",
+			path: "/accounts/x/login",
+			body: `{"username":"john","password":"secret"}`,
+			expected: AuditEntry{
+				Method: "POST",
+				Route:  "/accounts/{id}/login",
+				Status: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var entries []AuditEntry
+			SetAuditSink(AuditSinkFunc(func(ctx context.Context, entry AuditEntry) {
+				entries = append(entries, entry)
+			}))
+			defer SetAuditSink(nil)
+
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("X-API-Key", "key-1")
+
+			r := chi.NewRouter()
+			r.Post("/accounts/{id}/login", HandleTo(func(key APIKey, id int, login testLoginRequest) (int, any, error) {
+				return http.StatusNoContent, nil, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 audit entry, got %d", len(entries))
+			}
+
+			entry := entries[0]
+			if entry.Duration <= 0 {
+				t.Errorf("Expected a duration, got %v", entry.Duration)
+			}
+			entry.Duration = 0
+
+			if !reflect.DeepEqual(entry, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, entry)
+			}
+		})
+	}
+}
+
+func TestAuditPrincipalSecrets(t *testing.T) {
+	var entries []AuditEntry
+	SetAuditSink(AuditSinkFunc(func(ctx context.Context, entry AuditEntry) {
+		entries = append(entries, entry)
+	}))
+	defer SetAuditSink(nil)
+
+	testCases := []struct {
+		name     string
+		handler  interface{}
+		prepare  func(req *http.Request)
+		secret   string
+		expected any
+	}{
+		{
+			name:     "basic credentials",
+			handler:  func(creds BasicCredentials) (int, any, error) { return http.StatusNoContent, nil, nil },
+			prepare:  func(req *http.Request) { req.SetBasicAuth("john", "s3cret") },
+			secret:   "s3cret",
+			expected: BasicCredentials{Username: "john"},
+		},
+		{
+			name:     "api key",
+			handler:  func(key APIKey) (int, any, error) { return http.StatusNoContent, nil, nil },
+			prepare:  func(req *http.Request) { req.Header.Set("X-API-Key", "key-s3cret") },
+			secret:   "key-s3cret",
+			expected: KeyFingerprint("key-s3cret"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries = nil
+			req := httptest.NewRequest("GET", "/me", nil)
+			tc.prepare(req)
+			HandleTo(tc.handler).ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 audit entry, got %d", len(entries))
+			}
+			if strings.Contains(fmt.Sprintf("%+v", entries[0]), tc.secret) {
+				t.Errorf("Expected audit entry without %q, got %+v", tc.secret, entries[0])
+			}
+			if !reflect.DeepEqual(entries[0].Principal, tc.expected) {
+				t.Errorf("Expected principal %+v, got %+v", tc.expected, entries[0].Principal)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// ErrForbidden is returned when an authenticated request is not allowed. It
+// is mapped to 403.
+var ErrForbidden = errors.New("forbidden")
+
+// AuthorizeFunc decides whether a bound request may proceed. principal is
+// the bound claims, BasicCredentials or APIKey parameter, req the bound
+// struct parameter and params the path params, each nil or empty if the
+// handler takes none.
+type AuthorizeFunc func(ctx context.Context, principal any, req any, params ...any) error
+
+var (
+	principalTypesMu sync.RWMutex
+	principalTypes   = map[reflect.Type]bool{}
+)
+
+func init() {
+	RegisterError(ErrForbidden, http.StatusForbidden)
+
+	registerPrincipalType(typeOf[BasicCredentials]())
+	registerPrincipalType(typeOf[APIKey]())
+}
+
+// WithAuthorize runs authorize after binding and before the handler, e.g.
+// to check that users only update their own resources. Errors not mapped
+// through the error registry are answered with 403.
+func WithAuthorize(authorize AuthorizeFunc) Option {
+	return func(cfg *routeConfig) {
+		cfg.authorizers = append(cfg.authorizers, authorize)
+	}
+}
+
+func registerPrincipalType(t reflect.Type) {
+	principalTypesMu.Lock()
+	defer principalTypesMu.Unlock()
+
+	principalTypes[t] = true
+}
+
+func isPrincipalType(t reflect.Type) bool {
+	principalTypesMu.RLock()
+	defer principalTypesMu.RUnlock()
+
+	return principalTypes[t]
+}
+
+// authorize runs the route authorizers with the bound arguments.
+func authorize(r *http.Request, cfg *routeConfig, plan *bindPlan, args []reflect.Value) error {
+	if len(cfg.authorizers) == 0 {
+		return nil
+	}
+
+	principal, req, params := boundRoles(plan, args)
+	for _, authorize := range cfg.authorizers {
+		if err := authorize(r.Context(), principal, req, params...); err != nil {
+			if statusFromError(err) == http.StatusInternalServerError {
+				return errors.Join(ErrForbidden, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// boundRoles picks the principal, the bound struct and the path params out
+// of the bound arguments. The body struct is preferred over structs bound
+// from source tags only.
+func boundRoles(plan *bindPlan, args []reflect.Value) (principal any, req any, params []any) {
+	if len(args) == 0 {
+		return nil, nil, nil
+	}
+
+	for i, param := range plan.paramPlans() {
+		switch {
+		case param.kind == paramInjected && principal == nil && isPrincipalType(param.typ):
+			principal = args[i].Interface()
+		case param.kind == paramStruct && (req == nil || param.hasBody):
+			req = args[i].Interface()
+		case param.kind == paramPath:
+			params = append(params, args[i].Interface())
+		}
+	}
+
+	return principal, req, params
+}
@@ -0,0 +1,71 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testOwnUserOnly(ctx context.Context, principal any, req any, params ...any) error {
+	key, _ := principal.(APIKey)
+	if len(params) != 1 || string(key) != "user-"+params[0].(string) {
+		return errors.New("not the owner")
+	}
+
+	if req.(testUser).Name == "root" {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+func TestWithAuthorize(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "owner",
+			path:         "/users/42",
+			body:         `{"name":"John"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "other user",
+			path:         "/users/7",
+			body:         `{"name":"John"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "registered error status",
+			path:         "/users/42",
+			body:         `{"name":"root"}`,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("X-API-Key", "user-42")
+
+			r := chi.NewRouter()
+			r.Patch("/users/{id}", HandleTo(func(key APIKey, id string, u testUser) (testUser, error) {
+				return u, nil
+			}, WithAuthorize(testOwnUserOnly)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package bodyrest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// bindArgs builds the values for the parameters of plan from the request. On
+// failure it writes the error response itself and returns false.
+func bindArgs(w http.ResponseWriter, r *http.Request, cfg *routeConfig, plan *bindPlan) ([]reflect.Value, bool) {
+	if bindRecordDir == "" {
+		return bindRequest(w, r, cfg, plan)
+	}
+
+	w, rec := startBindRecording(w, r)
+	args, ok := bindRequest(w, r, cfg, plan)
+	if !ok {
+		rec.save(plan)
+	}
+
+	return args, ok
+}
+
+func bindRequest(w http.ResponseWriter, r *http.Request, cfg *routeConfig, plan *bindPlan) ([]reflect.Value, bool) {
+	if cfg.bindTimeout > 0 {
+		defer limitBindTime(w, r, cfg.bindTimeout)()
+	}
+
+	if !checkRawBody(w, r, cfg) {
+		return nil, false
+	}
+
+	n := plan.n
+	if n <= 0 {
+		return []reflect.Value{}, true
+	}
+
+	if (r.Method == http.MethodPost ||
+		r.Method == http.MethodPut ||
+		r.Method == http.MethodPatch) &&
+		(r.Body == nil || r.ContentLength == 0) {
+		log.Printf("request body is empty\n")
+		writeError(w, r, http.StatusBadRequest, nil)
+		return nil, false
+	}
+
+	var handlerArgsToCall []reflect.Value = make([]reflect.Value, n)
+	for i, param := range plan.paramPlans() {
+		switch param.kind {
+		case paramInjected:
+			value, err := param.inject(r)
+			if err != nil {
+				log.Printf("failed to inject %s: %v\n", param.typ, err)
+				writeError(w, r, statusFromError(err), err)
+				return nil, false
+			}
+
+			handlerArgsToCall[i] = value
+		case paramProvided:
+			if !plan.provides {
+				log.Printf("failed to provide %s: %v\n", param.typ, errNoProvider)
+				writeError(w, r, http.StatusInternalServerError, errNoProvider)
+				return nil, false
+			}
+
+			// begun by the invoker once all params are bound
+			handlerArgsToCall[i] = reflect.Zero(param.typ)
+		case paramExtraStruct:
+			log.Println("got more than one body struct")
+			writeError(w, r, http.StatusBadRequest, nil)
+			return nil, false
+		case paramMultipart:
+			err := r.ParseMultipartForm(maxMultipartMemory)
+			if err != nil {
+				log.Printf("failed to parse multipart form: %v\n", err)
+				writeError(w, r, bodyErrorStatus(err), err)
+				return nil, false
+			}
+
+			handlerArgsToCall[i] = reflect.ValueOf(*r.MultipartForm)
+		case paramStruct:
+			paramValue := reflect.New(param.typ)
+			if param.hasBody && cfg.methodAwareBinding && !isWriteMethod(r.Method) {
+				if err := bindQueryFields(r.URL.Query(), paramValue.Elem()); err != nil {
+					log.Printf("failed to bind query params: %v\n", err)
+					writeError(w, r, http.StatusBadRequest, err)
+					return nil, false
+				}
+
+				if err := validateRequiredFields(paramValue.Interface()); err != nil {
+					log.Printf("required fields are not valid: %v\n", err)
+					writeError(w, r, http.StatusBadRequest, err)
+					return nil, false
+				}
+			} else if param.hasBody {
+				err := decodeBody(w, r, cfg, paramValue.Interface())
+				if err != nil {
+					log.Printf("failed to parse request body: %v\n", err)
+					writeError(w, r, bodyErrorStatus(err), err)
+					return nil, false
+				}
+
+				if err := validateRequiredFields(paramValue.Interface()); err != nil {
+					log.Printf("required fields are not valid: %v\n", err)
+					writeError(w, r, http.StatusBadRequest, err)
+					return nil, false
+				}
+			}
+
+			err := bindSources(r, paramValue.Elem())
+			if err == nil {
+				if binder, ok := paramValue.Interface().(afterBinder); ok {
+					err = binder.afterBind()
+				}
+			}
+			if err != nil {
+				log.Printf("failed to bind request params: %v\n", err)
+				writeError(w, r, http.StatusBadRequest, err)
+				return nil, false
+			}
+
+			if binder, ok := paramValue.Interface().(AfterBinder); ok {
+				if err := binder.AfterBind(r); err != nil {
+					log.Printf("failed to complete %s: %v\n", param.typ, err)
+					writeError(w, r, afterBindStatus(err), err)
+					return nil, false
+				}
+			}
+
+			handlerArgsToCall[i] = paramValue.Elem()
+		case paramPath:
+			value, ok, err := boundPathParam(r, cfg, param.pathIndex)
+			if errors.Is(err, errNoRouteContext) {
+				log.Printf("failed to bind path param under index %d: %v; route the request with chi or use bodyrest.RequestWithParams\n", param.pathIndex, err)
+				writeError(w, r, http.StatusInternalServerError, err)
+				return nil, false
+			}
+			if err != nil {
+				log.Printf("failed to decode path param under index %d: %v\n", param.pathIndex, err)
+				writeError(w, r, http.StatusBadRequest, err)
+				return nil, false
+			}
+			if !ok {
+				continue
+			}
+
+			paramValue := reflect.New(param.typ).Elem()
+			if err := setFieldFromString(paramValue, value); err != nil {
+				log.Printf("failed to parse path param under index %d: %v\n", param.pathIndex, err)
+				writeError(w, r, http.StatusBadRequest, err)
+				return nil, false
+			}
+
+			handlerArgsToCall[i] = paramValue
+		}
+	}
+
+	zeroValueArguments := false
+	for i := 0; i < n; i++ {
+		if !handlerArgsToCall[i].IsValid() {
+			zeroValueArguments = true
+			break
+		}
+	}
+
+	if zeroValueArguments {
+		log.Println("handler has zero value arguments")
+		writeError(w, r, http.StatusBadRequest, nil)
+		return nil, false
+	}
+
+	return handlerArgsToCall, true
+}
+
+// pathParam returns the value of the n-th parameter matched by the route,
+// counting from zero in pattern order across mounted routers. It reads chi's
+// already parsed params and does not allocate.
+func pathParam(rctx *chi.Context, n int) (string, bool) {
+	if rctx == nil {
+		return "", false
+	}
+
+	for i, key := range rctx.URLParams.Keys {
+		if key == "*" {
+			continue
+		}
+
+		if n == 0 {
+			return rctx.URLParams.Values[i], true
+		}
+		n--
+	}
+
+	return "", false
+}
+
+// sourceTags are the struct tags that bind a field from a part of the request
+// other than the body.
+var sourceTags = []string{"path", "query", "cookie", "header"}
+
+func sourceTagOf(field reflect.StructField) (source string, name string, ok bool) {
+	sources := fieldSources(field)
+	if len(sources) == 0 {
+		return "", "", false
+	}
+
+	return sources[0].source, sources[0].name, true
+}
+
+type fieldSource struct {
+	source string
+	name   string
+}
+
+// fieldSources returns the sources field is bound from in order of
+// precedence. A source:"path,header" tag sets the order among the source tags
+// present; otherwise only the first source tag, in sourceTags order, is used.
+func fieldSources(field reflect.StructField) []fieldSource {
+	order, ok := field.Tag.Lookup("source")
+	if !ok {
+		for _, tag := range sourceTags {
+			if name, ok := field.Tag.Lookup(tag); ok {
+				return []fieldSource{{source: tag, name: name}}
+			}
+		}
+
+		return nil
+	}
+
+	var sources []fieldSource
+	for _, source := range strings.Split(order, ",") {
+		source = strings.TrimSpace(source)
+		if name, ok := field.Tag.Lookup(source); ok && slices.Contains(sourceTags, source) {
+			sources = append(sources, fieldSource{source: source, name: name})
+		}
+	}
+
+	return sources
+}
+
+// hasBodyFields reports whether t has exported fields that are not bound from
+// a source tag and therefore have to be decoded from the body.
+func hasBodyFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			if hasBodyFields(field.Type) {
+				return true
+			}
+			continue
+		}
+
+		if _, _, ok := sourceTagOf(field); !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmbeddedStruct reports whether field is an untagged embedded struct whose
+// fields are promoted into the parent, as encoding/json treats them.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	if !field.Anonymous || field.Type.Kind() != reflect.Struct || field.Tag.Get("json") != "" {
+		return false
+	}
+
+	_, _, tagged := sourceTagOf(field)
+	return !tagged
+}
+
+// afterBinder is implemented by bodyrest's own parameter types that need to
+// normalize themselves once the request has been bound.
+type afterBinder interface {
+	afterBind() error
+}
+
+// AfterBinder can be implemented by request structs, on the pointer
+// receiver, to derive computed fields once the body and the source tagged
+// fields are bound and validated, e.g. to normalize an email or split a
+// composite key. An error is answered with its registered status, or 400.
+type AfterBinder interface {
+	AfterBind(r *http.Request) error
+}
+
+func afterBindStatus(err error) int {
+	if status := statusFromError(err); status != http.StatusInternalServerError {
+		return status
+	}
+
+	return http.StatusBadRequest
+}
+
+// bindSources fills the source tagged fields of the struct v from the request.
+func bindSources(r *http.Request, v reflect.Value) error {
+	var query map[string][]string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if isEmbeddedStruct(field) && field.IsExported() {
+			if err := bindSources(r, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sources := fieldSources(field)
+		if len(sources) == 0 || !field.IsExported() {
+			continue
+		}
+
+		var bound *fieldSource
+		var boundValues []string
+		for _, src := range sources {
+			var values []string
+			switch src.source {
+			case "path":
+				value, err := urlParam(r, src.name)
+				if err != nil {
+					return sourceFieldError(field, src.source, src.name, err)
+				}
+				if value != "" {
+					values = []string{value}
+				}
+			case "query":
+				if query == nil {
+					query = r.URL.Query()
+				}
+				values = query[src.name]
+			case "cookie":
+				if value, ok := cookieValue(r, src.name); ok {
+					values = []string{value}
+				}
+			case "header":
+				values = r.Header.Values(src.name)
+			}
+
+			if len(values) == 0 {
+				continue
+			}
+
+			if bound == nil {
+				bound, boundValues = &src, values
+				if field.Tag.Get("conflict") != "error" {
+					break
+				}
+				continue
+			}
+
+			if !slices.Equal(values, boundValues) {
+				return sourceFieldError(field, src.source, src.name, fmt.Errorf("conflicts with %s param %q", bound.source, bound.name))
+			}
+		}
+
+		if bound == nil {
+			continue
+		}
+
+		if err := setFieldFromStrings(v.Field(i), boundValues); err != nil {
+			return sourceFieldError(field, bound.source, bound.name, err)
+		}
+	}
+
+	return nil
+}
+
+// sourceFieldError describes a failure to bind field, leaving out the error
+// details, which may quote the value, for sensitive fields.
+func sourceFieldError(field reflect.StructField, source, name string, err error) error {
+	if isSensitive(field) {
+		return fmt.Errorf("%s param %q: invalid %s", source, name, field.Type)
+	}
+
+	return fmt.Errorf("%s param %q: %w", source, name, err)
+}
+
+func setFieldFromStrings(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFieldFromString(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setFieldFromString(field, values[0])
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFieldFromString(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
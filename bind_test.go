@@ -0,0 +1,257 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestPathParamAllocations(t *testing.T) {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("org", "acme")
+	rctx.URLParams.Add("*", "")
+	rctx.URLParams.Add("id", "42")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if value, ok := pathParam(rctx, 1); !ok || value != "42" {
+			t.Fatalf("Expected 42, got %q", value)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkBindPathParams(b *testing.B) {
+	r := chi.NewRouter()
+	r.Get("/orgs/{org}/users/{id}", HandleTo(func(org string, id int) (int, any, error) {
+		return http.StatusNoContent, nil, nil
+	}))
+	req := httptest.NewRequest("GET", "/orgs/acme/users/42", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			b.Fatalf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+		}
+	}
+}
+
+func TestBindPlan(t *testing.T) {
+	handler := func(org string, req testUser, query url.Values, id int, extra testUser) {}
+	plan := newBindPlan(reflect.TypeOf(handler), 5)
+
+	expected := []paramKind{paramPath, paramStruct, paramInjected, paramPath, paramExtraStruct}
+	params := plan.paramPlans()
+	for i, kind := range expected {
+		if params[i].kind != kind {
+			t.Errorf("Expected param %d kind %d, got %d", i, kind, params[i].kind)
+		}
+	}
+
+	if params[3].pathIndex != 1 {
+		t.Errorf("Expected path index 1, got %d", params[3].pathIndex)
+	}
+
+	if &plan.paramPlans()[0] != &params[0] {
+		t.Errorf("Expected the plan to be built once")
+	}
+}
+
+func TestBindPathParamsMounted(t *testing.T) {
+	users := chi.NewRouter()
+	users.Get("/{id}", HandleTo(testGetOrgUser))
+
+	orgs := chi.NewRouter()
+	orgs.Route("/{org}", func(r chi.Router) {
+		r.Mount("/users", users)
+	})
+
+	r := chi.NewRouter()
+	r.Mount("/api/orgs", orgs)
+
+	req := httptest.NewRequest("GET", "/api/orgs/acme/users/42", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	expectedBody := `{"id":42,"org":"acme"}` + "\n"
+	if w.Body.String() != expectedBody {
+		t.Errorf("Expected body %s, got %s", expectedBody, w.Body.String())
+	}
+}
+
+type testInvite struct {
+	Email  string `json:"email"`
+	Org    string `query:"org"`
+	Domain string `json:"-"`
+}
+
+func (i *testInvite) AfterBind(r *http.Request) error {
+	if i.Org == "blocked" {
+		return ErrForbidden
+	}
+
+	user, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(i.Email)), "@")
+	if !ok {
+		return errors.New("email must contain @")
+	}
+
+	i.Email = user + "@" + domain
+	i.Domain = domain
+	return nil
+}
+
+func TestAfterBind(t *testing.T) {
+	testCases := []struct {
+		name           string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "computed fields", url: "/invites?org=acme", body: `{"email":" John@Example.COM "}`, expectedStatus: http.StatusOK, expectedBody: "john@example.com example.com acme"},
+		{name: "hook error", url: "/invites?org=acme", body: `{"email":"john"}`, expectedStatus: http.StatusBadRequest},
+		{name: "registered error", url: "/invites?org=blocked", body: `{"email":"john@example.com"}`, expectedStatus: http.StatusForbidden},
+	}
+
+	handler := HandleTo(func(i testInvite) (string, error) {
+		return i.Email + " " + i.Domain + " " + i.Org, nil
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && w.Body.String() != `"`+tc.expectedBody+`"`+"\n" {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type testScopedItems struct {
+	Tenant string `source:"path,header" path:"tenant" header:"X-Tenant"`
+	Region string `source:"header,query" header:"X-Region" query:"region" conflict:"error"`
+}
+
+func TestBindSourcePriority(t *testing.T) {
+	testCases := []struct {
+		name           string
+		url            string
+		header         map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "path wins over header", url: "/tenants/acme/items", header: map[string]string{"X-Tenant": "other"}, expectedStatus: http.StatusOK, expectedBody: `"acme:"`},
+		{name: "header fallback", url: "/items", header: map[string]string{"X-Tenant": "other"}, expectedStatus: http.StatusOK, expectedBody: `"other:"`},
+		{name: "agreeing sources", url: "/items?region=eu", header: map[string]string{"X-Region": "eu"}, expectedStatus: http.StatusOK, expectedBody: `":eu"`},
+		{name: "single source", url: "/items?region=eu", expectedStatus: http.StatusOK, expectedBody: `":eu"`},
+		{name: "conflicting sources", url: "/items?region=us", header: map[string]string{"X-Region": "eu"}, expectedStatus: http.StatusBadRequest},
+	}
+
+	handler := HandleTo(func(s testScopedItems) (string, error) {
+		return s.Tenant + ":" + s.Region, nil
+	})
+	r := chi.NewRouter()
+	r.Get("/tenants/{tenant}/items", handler)
+	r.Get("/items", handler)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			for key, value := range tc.header {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBindParamsStructWithBody(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        any
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "params struct before body",
+			handler: func(s testScopedItems, u testUser) (string, error) {
+				return s.Tenant + ":" + s.Region + ":" + u.Name, nil
+			},
+			url:            "/tenants/acme/items?region=eu",
+			body:           `{"name":"John"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"acme:eu:John"`,
+		},
+		{
+			name: "params struct after body",
+			handler: func(u testUser, s testScopedItems) (string, error) {
+				return s.Tenant + ":" + u.Name, nil
+			},
+			url:            "/tenants/acme/items",
+			body:           `{"name":"John"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"acme:John"`,
+		},
+		{
+			name: "two body structs",
+			handler: func(u testUser, other testUser) (string, error) {
+				return u.Name, nil
+			},
+			url:            "/tenants/acme/items",
+			body:           `{"name":"John"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Post("/tenants/{tenant}/items", HandleTo(tc.handler))
+
+			req := httptest.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
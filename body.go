@@ -0,0 +1,286 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DeprecationFunc is called when a client sends a field tagged deprecated.
+type DeprecationFunc func(r *http.Request, field string, message string)
+
+var deprecationFunc DeprecationFunc
+
+// SetDeprecationHandler sets the hook called for every deprecated field sent
+// by a client, e.g. to count clients still using old payload shapes.
+func SetDeprecationHandler(fn DeprecationFunc) {
+	deprecationFunc = fn
+}
+
+// checkRawBody runs the route checksum and signature checks and the body
+// transforms, if any, on the raw body and restores it so it can still be
+// decoded. On failure it writes the error response itself and returns false.
+func checkRawBody(w http.ResponseWriter, r *http.Request, cfg *routeConfig) bool {
+	if cfg.signatureVerifier == nil && len(cfg.checksums) == 0 && len(cfg.bodyTransforms) == 0 {
+		return true
+	}
+
+	data, err := readBody(r)
+	if err != nil && err != io.EOF {
+		log.Printf("failed to read request body: %v\n", err)
+		writeError(w, r, bodyErrorStatus(err), err)
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	for _, checksum := range cfg.checksums {
+		if err := checksum.verify(data, r.Header); err != nil {
+			log.Printf("request checksum is not valid: %v\n", err)
+			writeError(w, r, http.StatusBadRequest, err)
+			return false
+		}
+	}
+
+	if cfg.signatureVerifier != nil {
+		if err := cfg.signatureVerifier(data, r.Header); err != nil {
+			log.Printf("request signature is not valid: %v\n", err)
+			writeError(w, r, http.StatusUnauthorized, err)
+			return false
+		}
+	}
+
+	for _, transform := range cfg.bodyTransforms {
+		data, err = transform(data, r.Header)
+		if err != nil {
+			log.Printf("failed to transform request body: %v\n", err)
+			writeError(w, r, http.StatusBadRequest, err)
+			return false
+		}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+
+	return true
+}
+
+// readBody reads the whole request body.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, io.EOF
+	}
+
+	return io.ReadAll(r.Body)
+}
+
+// decodeBody reads the request body and decodes it as JSON into v, a pointer
+// to a struct or, for HandleBulk, to a slice of structs.
+func decodeBody(w http.ResponseWriter, r *http.Request, cfg *routeConfig, v any) error {
+	data, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	return decodeJSON(w, r, cfg, data, v)
+}
+
+// decodeJSON decodes data into v like a request body of the route of cfg,
+// e.g. the params of a JSON-RPC call.
+func decodeJSON(w http.ResponseWriter, r *http.Request, cfg *routeConfig, data []byte, v any) error {
+	var err error
+	limits := jsonLimitsFor(cfg)
+	if cfg.rejectDuplicateKeys || limits.scansRaw() {
+		if err := scanJSON(data, cfg.rejectDuplicateKeys, limits); err != nil {
+			return err
+		}
+	}
+
+	if cfg.keyNormalizer != nil {
+		data, err = normalizeKeys(data, reflect.TypeOf(v).Elem(), cfg.keyNormalizer)
+		if err != nil {
+			return newDecodeError(err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for _, configure := range cfg.decoderOptions {
+		configure(dec)
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return newDecodeError(err)
+	}
+
+	if limits.MaxElements > 0 {
+		if err := checkDecodedElements(reflect.ValueOf(v), limits.MaxElements); err != nil {
+			return err
+		}
+	}
+
+	if t := reflect.TypeOf(v).Elem(); t.Kind() == reflect.Struct {
+		warnDeprecatedFields(w, r, t, data)
+	}
+	reportUnknownFields(w, r, reflect.TypeOf(v).Elem(), data)
+	return nil
+}
+
+// warnDeprecatedFields adds a Deprecation and a Warning header for every
+// top-level field tagged deprecated:"..." that is present in data.
+func warnDeprecatedFields(w http.ResponseWriter, r *http.Request, t reflect.Type, data []byte) {
+	var deprecated []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("deprecated"); ok {
+			deprecated = append(deprecated, t.Field(i))
+		}
+	}
+
+	if len(deprecated) == 0 {
+		return
+	}
+
+	var sent map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sent); err != nil {
+		return
+	}
+
+	for _, field := range deprecated {
+		name := jsonFieldName(field)
+		for key := range sent {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+
+			message := field.Tag.Get("deprecated")
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Warning", fmt.Sprintf(`299 - "field %s is deprecated: %s"`, name, message))
+			if deprecationFunc != nil {
+				deprecationFunc(r, name, message)
+			}
+			break
+		}
+	}
+}
+
+// jsonFieldName returns the name encoding/json uses for field.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// UnknownFieldsFunc is called with the dotted paths of JSON keys that do not
+// match any field of the request struct.
+type UnknownFieldsFunc func(r *http.Request, fields []string)
+
+var (
+	unknownFieldsFunc   UnknownFieldsFunc
+	unknownFieldsHeader string
+)
+
+// SetUnknownFieldsHandler sets the hook called when a request body contains
+// keys the request struct does not know about. Unlike rejecting such bodies,
+// this only reports them, e.g. to measure client drift.
+func SetUnknownFieldsHandler(fn UnknownFieldsFunc) {
+	unknownFieldsFunc = fn
+}
+
+// SetUnknownFieldsHeader makes bodyrest list unknown body keys in the given
+// response header. An empty name disables the header.
+func SetUnknownFieldsHeader(name string) {
+	unknownFieldsHeader = name
+}
+
+func reportUnknownFields(w http.ResponseWriter, r *http.Request, t reflect.Type, data []byte) {
+	if unknownFieldsFunc == nil && unknownFieldsHeader == "" {
+		return
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return
+	}
+
+	unknown := unknownFields(t, value, "")
+	if len(unknown) == 0 {
+		return
+	}
+
+	if unknownFieldsHeader != "" {
+		w.Header().Set(unknownFieldsHeader, strings.Join(unknown, ","))
+	}
+	if unknownFieldsFunc != nil {
+		unknownFieldsFunc(r, unknown)
+	}
+}
+
+// unknownFields returns the paths of keys in value that t cannot hold.
+func unknownFields(t reflect.Type, value any, prefix string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		var unknown []string
+		for _, item := range v {
+			unknown = append(unknown, unknownFields(t.Elem(), item, prefix)...)
+		}
+		return unknown
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		var unknown []string
+		for key, child := range v {
+			field, ok := lookupJSONField(t, key)
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			if !ok {
+				unknown = append(unknown, path)
+				continue
+			}
+			unknown = append(unknown, unknownFields(field.Type, child, path)...)
+		}
+		sort.Strings(unknown)
+		return unknown
+	default:
+		return nil
+	}
+}
+
+// lookupJSONField finds the field of struct t that encoding/json would decode
+// key into, including fields promoted from embedded structs.
+func lookupJSONField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			if found, ok := lookupJSONField(field.Type, key); ok {
+				return found, true
+			}
+			continue
+		}
+
+		if strings.EqualFold(jsonFieldName(field), key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
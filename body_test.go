@@ -0,0 +1,140 @@
+package bodyrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testRenameRequest struct {
+	Name     string `json:"name,omitempty"`
+	FullName string `json:"full_name,omitempty" deprecated:"use name"`
+}
+
+func testRename(req testRenameRequest) (int, any, error) {
+	return http.StatusNoContent, nil, nil
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	var reported []string
+	SetDeprecationHandler(func(r *http.Request, field string, message string) {
+		reported = append(reported, field+": "+message)
+	})
+	defer SetDeprecationHandler(nil)
+
+	testCases := []struct {
+		name            string
+		jsonPayload     string
+		expectedWarning string
+		expectedReports int
+	}{
+		{
+			name:        "Current field",
+			jsonPayload: `{"name":"john"}`,
+		},
+		{
+			name:            "Deprecated field",
+			jsonPayload:     `{"full_name":"john"}`,
+			expectedWarning: `299 - "field full_name is deprecated: use name"`,
+			expectedReports: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reported = nil
+
+			req, err := http.NewRequest("POST", "/rename", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/rename", HandleTo(testRename))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+			}
+
+			if w.Header().Get("Warning") != tc.expectedWarning {
+				t.Errorf("Expected warning %q, got %q", tc.expectedWarning, w.Header().Get("Warning"))
+			}
+
+			if len(reported) != tc.expectedReports {
+				t.Errorf("Expected %d reports, got %v", tc.expectedReports, reported)
+			}
+		})
+	}
+}
+
+type testNestedRequest struct {
+	Name    string `json:"name"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+	Tags []struct {
+		Label string `json:"label"`
+	} `json:"tags,omitempty"`
+}
+
+func TestUnknownFields(t *testing.T) {
+	var reported []string
+	SetUnknownFieldsHandler(func(r *http.Request, fields []string) {
+		reported = fields
+	})
+	SetUnknownFieldsHeader("X-Unknown-Fields")
+	defer SetUnknownFieldsHandler(nil)
+	defer SetUnknownFieldsHeader("")
+
+	testCases := []struct {
+		name           string
+		jsonPayload    string
+		expectedHeader string
+	}{
+		{
+			name:        "Only known fields",
+			jsonPayload: `{"name":"john","address":{"city":"Oslo"}}`,
+		},
+		{
+			name:           "Unknown top level and nested fields",
+			jsonPayload:    `{"name":"john","nick":"jj","address":{"city":"Oslo","zip":"0150"},"tags":[{"label":"a","color":"red"}]}`,
+			expectedHeader: "address.zip,nick,tags.color",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reported = nil
+
+			req, err := http.NewRequest("POST", "/test", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/test", HandleTo(func(req testNestedRequest) (int, any, error) {
+				return http.StatusNoContent, nil, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+			}
+
+			if w.Header().Get("X-Unknown-Fields") != tc.expectedHeader {
+				t.Errorf("Expected header %q, got %q", tc.expectedHeader, w.Header().Get("X-Unknown-Fields"))
+			}
+
+			if strings.Join(reported, ",") != tc.expectedHeader {
+				t.Errorf("Expected reported fields %q, got %v", tc.expectedHeader, reported)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package bodyrest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ErrCircuitOpen rejects requests to a route whose circuit breaker is open.
+// It wraps ErrServiceUnavailable and so is mapped to 503.
+var ErrCircuitOpen = fmt.Errorf("circuit open: %w", ErrServiceUnavailable)
+
+// CircuitBreaker guards routes wrapped by HandleTo. Routes are keyed by
+// method and route pattern, e.g. "GET /users/{id}".
+type CircuitBreaker interface {
+	// Allow reports whether a request for route may proceed. It is asked
+	// before the request is bound.
+	Allow(route string) bool
+	// Record reports the outcome of an allowed request once its response
+	// is written. Responses below 500 count as successes.
+	Record(route string, success bool)
+}
+
+// WithCircuitBreaker rejects requests with ErrCircuitOpen while cb does not
+// allow them, before any binding or handler work is done.
+func WithCircuitBreaker(cb CircuitBreaker) Option {
+	return func(cfg *routeConfig) {
+		cfg.circuitBreaker = cb
+	}
+}
+
+// enterCircuit asks the route's circuit breaker to let r through. If it
+// does, it returns the writer to respond with and a func to call once the
+// response is written; otherwise it writes the 503 itself.
+func enterCircuit(w http.ResponseWriter, r *http.Request, cb CircuitBreaker) (http.ResponseWriter, func(), bool) {
+	route := r.Method + " " + routePattern(r)
+	if !cb.Allow(route) {
+		writeError(w, r, statusFromError(ErrCircuitOpen), ErrCircuitOpen)
+		return w, nil, false
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	return rec, func() {
+		cb.Record(route, rec.statusCode() < http.StatusInternalServerError)
+	}, true
+}
+
+// routePattern returns the matched route pattern of r, or its path when
+// there is none.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+
+	return r.URL.Path
+}
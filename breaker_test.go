@@ -0,0 +1,71 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// testBreaker opens a route after its first failure.
+type testBreaker struct {
+	open    map[string]bool
+	records []string
+}
+
+func (b *testBreaker) Allow(route string) bool {
+	return !b.open[route]
+}
+
+func (b *testBreaker) Record(route string, success bool) {
+	if !success {
+		b.open[route] = true
+	}
+	b.records = append(b.records, route)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	errDownstream := errors.New("downstream failed")
+	breaker := &testBreaker{open: map[string]bool{}}
+
+	r := chi.NewRouter()
+	r.Post("/orgs/{org}/users", HandleTo(func(org string, u testUser) (testUser, error) {
+		if u.Name == "fail" {
+			return testUser{}, errDownstream
+		}
+		return u, nil
+	}, WithCircuitBreaker(breaker)))
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "closed", body: `{"name":"john"}`, expectedStatus: http.StatusOK},
+		{name: "client error keeps circuit closed", body: `{"name":`, expectedStatus: http.StatusBadRequest},
+		{name: "failure", body: `{"name":"fail"}`, expectedStatus: http.StatusInternalServerError},
+		{name: "open", body: `{"name":"john"}`, expectedStatus: http.StatusServiceUnavailable},
+		{name: "open before binding", body: `{"name":`, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orgs/acme/users", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+
+	expected := []string{"POST /orgs/{org}/users", "POST /orgs/{org}/users", "POST /orgs/{org}/users"}
+	if !reflect.DeepEqual(breaker.records, expected) {
+		t.Errorf("Expected records %v, got %v", expected, breaker.records)
+	}
+}
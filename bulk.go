@@ -0,0 +1,146 @@
+package bodyrest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// BulkResult is the outcome of one item of a bulk request.
+type BulkResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Data   any    `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Violations lists the invalid fields of an item failing validation.
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// WithBulkConcurrency sets how many items of a HandleBulk request are
+// processed at the same time. Items are processed one by one by default.
+func WithBulkConcurrency(n int) Option {
+	return func(cfg *routeConfig) {
+		if n > 0 {
+			cfg.bulkConcurrency = n
+		}
+	}
+}
+
+// HandleBulk exposes a handler written for a single item, e.g.
+// func(req T) (Res, error), as an endpoint accepting a JSON array of items.
+// The handler is invoked for every item and the response is a 207 Multi-Status
+// array of BulkResult in request order. Parameters before the item are bound
+// as in HandleTo.
+func HandleBulk(handlerFunc interface{}, opts ...Option) http.HandlerFunc {
+	handlerType := reflect.TypeOf(handlerFunc)
+	if handlerType.Kind() != reflect.Func {
+		log.Fatal("Handler is not a function")
+	}
+
+	if handlerType.NumIn() == 0 || handlerType.In(handlerType.NumIn()-1).Kind() != reflect.Struct {
+		log.Fatal("bulk handler must take the item struct as its last parameter")
+	}
+
+	if resultFormOf(handlerType) != resultValue {
+		log.Fatal("bulk handler must return a value and an error")
+	}
+
+	itemType := handlerType.In(handlerType.NumIn() - 1)
+	cfg := newRouteConfig(opts)
+	plan := newBindPlan(handlerType, handlerType.NumIn()-1)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer recoverHandler(w, r)
+
+		finish, ok := admitRequest(w, r)
+		if !ok {
+			return
+		}
+		defer finish()
+
+		handlerArgsToCall, ok := bindArgs(w, r, cfg, plan)
+		if !ok {
+			return
+		}
+		defer releaseArgs(handlerArgsToCall)
+		r = withPrincipalRoles(r, plan, handlerArgsToCall)
+
+		if r.Body == nil || r.ContentLength == 0 {
+			log.Printf("request body is empty\n")
+			writeError(w, r, http.StatusBadRequest, nil)
+			return
+		}
+
+		items := reflect.New(reflect.SliceOf(itemType))
+		if err := decodeBody(w, r, cfg, items.Interface()); err != nil {
+			log.Printf("failed to parse request body: %v\n", err)
+			writeError(w, r, bodyErrorStatus(err), err)
+			return
+		}
+
+		handlerValue := reflect.ValueOf(handlerFunc)
+		results := make([]BulkResult, items.Elem().Len())
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, cfg.bulkConcurrency)
+		for i := range results {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				item := items.Elem().Index(i)
+				results[i] = invokeBulkItem(r, handlerValue, handlerArgsToCall, item, i)
+			}(i)
+		}
+		wg.Wait()
+
+		writeResponse(w, r, cfg, Response{Status: http.StatusMultiStatus, Body: results})
+	})
+}
+
+func invokeBulkItem(r *http.Request, handlerValue reflect.Value, boundArgs []reflect.Value, item reflect.Value, index int) (result BulkResult) {
+	defer func() {
+		if value := recover(); value != nil {
+			log.Printf("bulk item %d panicked: %v\n", index, value)
+			status := http.StatusInternalServerError
+			result = BulkResult{Index: index, Status: status, Error: http.StatusText(status)}
+		}
+	}()
+
+	if err := validateRequiredFields(item.Interface()); err != nil {
+		result = BulkResult{Index: index, Status: http.StatusBadRequest, Error: http.StatusText(http.StatusBadRequest)}
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			result.Violations = validationErr.Violations
+		}
+		return result
+	}
+
+	if binder, ok := item.Addr().Interface().(AfterBinder); ok {
+		if err := binder.AfterBind(r); err != nil {
+			log.Printf("failed to complete bulk item %d: %v\n", index, err)
+			status := afterBindStatus(err)
+			return BulkResult{Index: index, Status: status, Error: http.StatusText(status)}
+		}
+	}
+
+	args := append(append([]reflect.Value{}, boundArgs...), item)
+	out := handlerValue.Call(args)
+
+	if err, _ := out[1].Interface().(error); err != nil {
+		log.Printf("bulk item %d returned error: %v\n", index, err)
+		status := statusFromError(err)
+		return BulkResult{Index: index, Status: status, Error: http.StatusText(status)}
+	}
+
+	result = BulkResult{Index: index, Status: http.StatusOK}
+	if !isNilValue(out[0]) {
+		result.Data = out[0].Interface()
+	}
+
+	return result
+}
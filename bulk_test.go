@@ -0,0 +1,87 @@
+package bodyrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testImportUser(u testUser) (testUser, error) {
+	if u.Name == "exists" {
+		return testUser{}, errTestUserExists
+	}
+
+	return testUser{Name: strings.ToUpper(u.Name)}, nil
+}
+
+func TestHandleBulk(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	testCases := []struct {
+		name           string
+		jsonPayload    string
+		opts           []Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Per item results",
+			jsonPayload:    `[{"name":"john"},{"name":"exists"},{"name":""}]`,
+			expectedStatus: http.StatusMultiStatus,
+			expectedBody:   `[{"index":0,"status":200,"data":{"name":"JOHN"}},{"index":1,"status":409,"error":"Conflict"},{"index":2,"status":400,"error":"Bad Request","violations":[{"field":"name","message":"is required"}]}]`,
+		},
+		{
+			name:           "Concurrent items keep order",
+			jsonPayload:    `[{"name":"a"},{"name":"b"},{"name":"c"}]`,
+			opts:           []Option{WithBulkConcurrency(3)},
+			expectedStatus: http.StatusMultiStatus,
+			expectedBody:   `[{"index":0,"status":200,"data":{"name":"A"}},{"index":1,"status":200,"data":{"name":"B"}},{"index":2,"status":200,"data":{"name":"C"}}]`,
+		},
+		{
+			name:           "Element limit",
+			jsonPayload:    `[{"name":"a"},{"name":"b"},{"name":"c"}]`,
+			opts:           []Option{WithJSONLimits(JSONLimits{MaxElements: 2})},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+		{
+			name:           "Duplicate keys rejected",
+			jsonPayload:    `[{"name":"a","name":"b"}]`,
+			opts:           []Option{WithRejectDuplicateKeys()},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+		{
+			name:           "Body is not an array",
+			jsonPayload:    `{"name":"john"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/users/bulk", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/users/bulk", HandleBulk(testImportUser, tc.opts...))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
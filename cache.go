@@ -0,0 +1,225 @@
+package bodyrest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCacheControl sets the Cache-Control header of successful responses
+// encoded by bodyrest for the route, e.g. "public, max-age=60", unless the
+// handler sets one itself. Error responses are always sent with no-store.
+func WithCacheControl(policy string) Option {
+	return func(cfg *routeConfig) {
+		cfg.cacheControl = policy
+	}
+}
+
+// CachedResponse is a response stored by WithResponseCache.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// CacheStore stores cached responses for WithResponseCache, e.g. in memory
+// with NewMemoryCache or in a shared cache such as Redis.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (CachedResponse, bool)
+	Set(ctx context.Context, key string, resp CachedResponse, ttl time.Duration)
+}
+
+type responseCache struct {
+	store CacheStore
+	ttl   time.Duration
+}
+
+// WithResponseCache caches the 200 responses of the route's GET requests in
+// store for ttl, keyed by the route pattern and the values of the bound
+// handler parameters, hashed so credentials never reach the store.
+// Responses setting cookies or marked Cache-Control private or no-store are
+// not cached.
+// Cached requests are still bound, gated and authorized, but the handler is
+// not called. Cached responses carry an ETag and are answered with 304 when
+// the client already has them.
+func WithResponseCache(store CacheStore, ttl time.Duration) Option {
+	return func(cfg *routeConfig) {
+		cfg.responseCache = &responseCache{store: store, ttl: ttl}
+	}
+}
+
+// serve answers r from the cache, or through next, storing its response if
+// it is a 200. Requests whose parameters cannot be keyed go through next.
+func (c *responseCache) serve(w http.ResponseWriter, r *http.Request, plan *bindPlan, args []reflect.Value, next func(w http.ResponseWriter)) {
+	key, err := responseCacheKey(r, plan, args)
+	if err != nil {
+		next(w)
+		return
+	}
+
+	if cached, ok := c.store.Get(r.Context(), key); ok {
+		writeCached(w, r, cached)
+		return
+	}
+
+	buf := &bufferedResponseWriter{header: http.Header{}}
+	next(buf)
+
+	cached := CachedResponse{Status: buf.statusCode(), Header: buf.header, Body: buf.body.Bytes()}
+	if cached.Status == http.StatusOK && isShareable(cached.Header) {
+		sum := sha256.Sum256(cached.Body)
+		cached.Header.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		c.store.Set(r.Context(), key, cached, c.ttl)
+	}
+
+	writeCached(w, r, cached)
+}
+
+// isShareable reports whether a response with header may be served to
+// other clients: it sets no cookie and its Cache-Control is neither private
+// nor no-store.
+func isShareable(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if strings.EqualFold(name, "private") || strings.EqualFold(name, "no-store") {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// responseCacheKey keys r by its route, bound parameters and the parts of
+// the request that change the encoded response. The key is hashed, so
+// bound credentials never reach the store.
+func responseCacheKey(r *http.Request, plan *bindPlan, args []reflect.Value) (string, error) {
+	var buf bytes.Buffer
+	if err := writeParamsKey(&buf, plan, args); err != nil {
+		return "", err
+	}
+
+	roles, _ := r.Context().Value(rolesContextKey{}).([]string)
+	key := strings.Join([]string{
+		routePattern(r),
+		buf.String(),
+		r.URL.Query().Get(fieldsQueryParam),
+		r.URL.Query().Get(viewQueryParam),
+		strings.Join(roles, ","),
+		r.Header.Get("Accept-Encoding"),
+	}, "\n")
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func writeCached(w http.ResponseWriter, r *http.Request, cached CachedResponse) {
+	for key, values := range cached.Header {
+		w.Header()[key] = append([]string(nil), values...)
+	}
+
+	if etag := cached.Header.Get("ETag"); etag != "" && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(cached.Status)
+	if _, err := w.Write(cached.Body); err != nil {
+		log.Printf("failed to write response body: %v\n", err)
+	}
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag,
+// using the weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferedResponseWriter holds a response in memory.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// MemoryCache is an in-process CacheStore.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	resp    CachedResponse
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]memoryCacheEntry{}}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) (CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return CachedResponse{}, false
+	}
+
+	return entry.resp, true
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, resp CachedResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = memoryCacheEntry{resp: resp, expires: time.Now().Add(ttl)}
+}
@@ -0,0 +1,165 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCacheControl(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		handler              interface{}
+		jsonPayload          string
+		expectedStatus       int
+		expectedCacheControl string
+	}{
+		{name: "success", handler: testCreateUser, jsonPayload: `{"name":"john"}`, expectedStatus: http.StatusCreated, expectedCacheControl: "public, max-age=60"},
+		{name: "success without body", handler: testCreateUser, jsonPayload: `{"name":"empty"}`, expectedStatus: http.StatusNoContent, expectedCacheControl: "public, max-age=60"},
+		{name: "handler error", handler: testCreateUser, jsonPayload: `{"name":"exists"}`, expectedStatus: http.StatusConflict, expectedCacheControl: "no-store"},
+		{name: "binding error", handler: testCreateUser, jsonPayload: `{"name":`, expectedStatus: http.StatusBadRequest, expectedCacheControl: "no-store"},
+		{
+			name: "handler header wins",
+			handler: func(u testUser) (any, error) {
+				return WithHeaders(u, map[string]string{"Cache-Control": "private"}), nil
+			},
+			jsonPayload:          `{"name":"john"}`,
+			expectedStatus:       http.StatusOK,
+			expectedCacheControl: "private",
+		},
+	}
+
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := HandleTo(tc.handler, WithCacheControl("public, max-age=60"))
+
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.jsonPayload))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if cacheControl := w.Header().Get("Cache-Control"); cacheControl != tc.expectedCacheControl {
+				t.Errorf("Expected Cache-Control %q, got %q", tc.expectedCacheControl, cacheControl)
+			}
+		})
+	}
+}
+
+func TestWithResponseCache(t *testing.T) {
+	calls := 0
+	r := chi.NewRouter()
+	r.Get("/orgs/{org}/users/{id}", HandleTo(func(org string, id int) (any, error) {
+		calls++
+		user := map[string]any{"org": org, "id": id, "call": calls}
+		switch id {
+		case 0:
+			return nil, errTestUserExists
+		case 7:
+			return WithHeaders(user, map[string]string{"Set-Cookie": "session=abc"}), nil
+		case 8:
+			return WithHeaders(user, map[string]string{"Cache-Control": "private, max-age=60"}), nil
+		}
+		return user, nil
+	}, WithResponseCache(NewMemoryCache(), time.Minute)))
+
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	testCases := []struct {
+		name           string
+		url            string
+		ifNoneMatch    bool
+		expectedStatus int
+		expectedCalls  int
+		expectedBody   string
+		uncached       bool
+	}{
+		{name: "miss", url: "/orgs/acme/users/1", expectedStatus: http.StatusOK, expectedCalls: 1, expectedBody: `{"call":1,"id":1,"org":"acme"}`},
+		{name: "hit", url: "/orgs/acme/users/1", expectedStatus: http.StatusOK, expectedCalls: 1, expectedBody: `{"call":1,"id":1,"org":"acme"}`},
+		{name: "not modified", url: "/orgs/acme/users/1", ifNoneMatch: true, expectedStatus: http.StatusNotModified, expectedCalls: 1},
+		{name: "other params", url: "/orgs/acme/users/2", expectedStatus: http.StatusOK, expectedCalls: 2, expectedBody: `{"call":2,"id":2,"org":"acme"}`},
+		{name: "errors not cached", url: "/orgs/acme/users/0", expectedStatus: http.StatusConflict, expectedCalls: 3},
+		{name: "errors not cached again", url: "/orgs/acme/users/0", expectedStatus: http.StatusConflict, expectedCalls: 4},
+		{name: "cookies not cached", url: "/orgs/acme/users/7", expectedStatus: http.StatusOK, expectedCalls: 5, uncached: true},
+		{name: "cookies not cached again", url: "/orgs/acme/users/7", expectedStatus: http.StatusOK, expectedCalls: 6, expectedBody: `{"call":6,"id":7,"org":"acme"}`, uncached: true},
+		{name: "private not cached", url: "/orgs/acme/users/8", expectedStatus: http.StatusOK, expectedCalls: 7, uncached: true},
+		{name: "private not cached again", url: "/orgs/acme/users/8", expectedStatus: http.StatusOK, expectedCalls: 8, uncached: true},
+	}
+
+	var etag string
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			if tc.ifNoneMatch {
+				req.Header.Set("If-None-Match", etag)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected %d handler calls, got %d", tc.expectedCalls, calls)
+			}
+
+			if tc.expectedBody != "" && strings.Join(strings.Fields(w.Body.String()), "") != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+
+			if w.Code == http.StatusOK && !tc.uncached {
+				if w.Header().Get("ETag") == "" {
+					t.Error("Expected an ETag header")
+				}
+				etag = w.Header().Get("ETag")
+			}
+		})
+	}
+}
+
+type testRecordingCache struct {
+	CacheStore
+	keys []string
+}
+
+func (c *testRecordingCache) Set(ctx context.Context, key string, resp CachedResponse, ttl time.Duration) {
+	c.keys = append(c.keys, key)
+	c.CacheStore.Set(ctx, key, resp, ttl)
+}
+
+func TestResponseCacheKeys(t *testing.T) {
+	store := &testRecordingCache{CacheStore: NewMemoryCache()}
+	handler := HandleTo(func(creds BasicCredentials, q testMeQuery) (string, error) {
+		return creds.Username + ":" + q.User, nil
+	}, WithResponseCache(store, time.Minute))
+
+	for _, user := range []string{"alice", "bob"} {
+		req := httptest.NewRequest("GET", "/me?user="+user, nil)
+		req.SetBasicAuth("admin", "s3cret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if expected := `"admin:` + user + `"`; strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	}
+
+	if len(store.keys) != 2 {
+		t.Fatalf("Expected 2 cache entries, got %d", len(store.keys))
+	}
+	for _, key := range store.keys {
+		if strings.Contains(key, "s3cret") || strings.Contains(key, "admin") {
+			t.Errorf("Expected key without credentials, got %q", key)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package bodyrest
+
+import "fmt"
+
+// Problem is a misconfiguration found by Check. Method and Pattern are empty
+// for problems not tied to a route.
+type Problem struct {
+	Method  string
+	Pattern string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Pattern == "" {
+		return p.Message
+	}
+
+	return fmt.Sprintf("%s %s: %s", p.Method, p.Pattern, p.Message)
+}
+
+// Check walks the routes registered on rt and reports the problems that
+// would otherwise only surface as failed requests: unsupported param types,
+// more path params than placeholders, several body structs and a missing
+// rest error handler. Unsupported return values already fail HandleTo at
+// registration. Services can call it at startup and refuse to boot when it
+// returns problems.
+func Check(rt *Router) []Problem {
+	var problems []Problem
+	if restErrorFunc == nil {
+		problems = append(problems, Problem{Message: "no rest error handler set, see SetRestErrorHandler"})
+	}
+
+	for _, route := range rt.Routes() {
+		for _, mismatch := range routeMismatches(route) {
+			problems = append(problems, Problem{Method: route.Method, Pattern: route.Pattern, Message: mismatch})
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,67 @@
+package bodyrest
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type testBadParams struct {
+	Since map[string]string `query:"since"`
+}
+
+func TestCheck(t *testing.T) {
+	saved := restErrorFunc
+	defer func() { restErrorFunc = saved }()
+
+	testCases := []struct {
+		name         string
+		errorHandler RestErrorFunc
+		register     func(rt *Router)
+		expected     []Problem
+	}{
+		{
+			name:         "No problems",
+			errorHandler: func(w http.ResponseWriter, r *http.Request, status int) {},
+			register: func(rt *Router) {
+				rt.Post("/users", testCreateUser)
+				rt.Get("/orgs/{org}/repos/{repo}/issues/{num}", testGetIssue)
+			},
+		},
+		{
+			name: "Missing error handler",
+			register: func(rt *Router) {
+				rt.Post("/users", testCreateUser)
+			},
+			expected: []Problem{{Message: "no rest error handler set, see SetRestErrorHandler"}},
+		},
+		{
+			name:         "Route problems",
+			errorHandler: func(w http.ResponseWriter, r *http.Request, status int) {},
+			register: func(rt *Router) {
+				rt.Get("/items/{id}", func(id int, p testBadParams) (int, error) { return id, nil })
+				rt.Post("/users/{id}", func(u testUser, other testUser) (testUser, error) { return u, nil })
+				rt.Get("/pairs/{a}", func(a, b string) (string, error) { return a, nil })
+			},
+			expected: []Problem{
+				{Method: http.MethodGet, Pattern: "/items/{id}", Message: `query param "since" has unsupported type map[string]string`},
+				{Method: http.MethodPost, Pattern: "/users/{id}", Message: "more than one body struct: bodyrest.testUser"},
+				{Method: http.MethodGet, Pattern: "/pairs/{a}", Message: "handler takes 2 path params, pattern has 1 placeholders"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			restErrorFunc = tc.errorHandler
+
+			rt := NewRouter(nil)
+			tc.register(rt)
+
+			problems := Check(rt)
+			if !reflect.DeepEqual(problems, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package bodyrest
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+type bodyChecksum struct {
+	header  string
+	newHash func() hash.Hash
+}
+
+// WithContentMD5 rejects requests whose Content-MD5 header does not match
+// the MD5 of the received body with 400.
+func WithContentMD5() Option {
+	return WithChecksum("Content-MD5", md5.New)
+}
+
+// WithChecksum rejects requests whose header does not carry the checksum of
+// the received body, computed with newHash and encoded as base64 or hex,
+// with 400. Requests without the header are rejected too.
+func WithChecksum(header string, newHash func() hash.Hash) Option {
+	return func(cfg *routeConfig) {
+		cfg.checksums = append(cfg.checksums, bodyChecksum{header: header, newHash: newHash})
+	}
+}
+
+func (c bodyChecksum) verify(body []byte, header http.Header) error {
+	expected := strings.TrimSpace(header.Get(c.header))
+	if expected == "" {
+		return fmt.Errorf("missing %s header", c.header)
+	}
+
+	h := c.newHash()
+	h.Write(body)
+	sum := h.Sum(nil)
+
+	if subtle.ConstantTimeCompare([]byte(base64.StdEncoding.EncodeToString(sum)), []byte(expected)) == 1 ||
+		subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum)), []byte(strings.ToLower(expected))) == 1 {
+		return nil
+	}
+
+	return fmt.Errorf("%s does not match the request body", c.header)
+}
@@ -0,0 +1,53 @@
+package bodyrest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestChecksumOptions(t *testing.T) {
+	payload := `{"name":"john"}`
+	md5Sum := md5.Sum([]byte(payload))
+	shaSum := sha256.Sum256([]byte(payload))
+
+	testCases := []struct {
+		name           string
+		opt            Option
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "Valid Content-MD5", opt: WithContentMD5(), headers: map[string]string{"Content-MD5": base64.StdEncoding.EncodeToString(md5Sum[:])}, expectedStatus: http.StatusOK},
+		{name: "Wrong Content-MD5", opt: WithContentMD5(), headers: map[string]string{"Content-MD5": "AAAAAAAAAAAAAAAAAAAAAA=="}, expectedStatus: http.StatusBadRequest},
+		{name: "Missing Content-MD5", opt: WithContentMD5(), expectedStatus: http.StatusBadRequest},
+		{name: "Valid hex SHA-256", opt: WithChecksum("X-Checksum-SHA256", sha256.New), headers: map[string]string{"X-Checksum-SHA256": hex.EncodeToString(shaSum[:])}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(testImportUser, tc.opt))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package bodyrest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// ChunkStream exchanges newline-delimited JSON with the client of a
+// HandleChunked handler.
+type ChunkStream interface {
+	// Send encodes v as one JSON line and flushes it to the client.
+	Send(v any) error
+	// Recv decodes the next JSON value of the request body into v. It
+	// returns io.EOF once the body is exhausted.
+	Recv(v any) error
+	// Flushing reports whether chunks reach the client as they are sent.
+	// It is false when the response writer cannot flush, in which case the
+	// chunks are buffered and written when the handler returns.
+	Flushing() bool
+	// Context is canceled when the client disconnects.
+	Context() context.Context
+}
+
+var chunkStreamType = reflect.TypeOf((*ChunkStream)(nil)).Elem()
+
+// HandleChunked binds the request like HandleTo and then lets the handler
+// stream newline-delimited JSON chunks, e.g. progress reports, flushing each
+// one. The handler must take bodyrest.ChunkStream as its last parameter and
+// return an error, e.g. func(id int, stream bodyrest.ChunkStream) error.
+//
+// Over HTTP/2, including h2c, the handler can keep reading the request body
+// with Recv while sending; over HTTP/1.x full duplex is enabled when the
+// server supports it. Handlers reading with Recv should not take a body
+// struct parameter, since binding would consume the body. Errors returned
+// before the first chunk go through the rest error handler.
+func HandleChunked(handlerFunc interface{}, opts ...Option) http.HandlerFunc {
+	handlerType := reflect.TypeOf(handlerFunc)
+	if handlerType.Kind() != reflect.Func {
+		log.Fatal("Handler is not a function")
+	}
+
+	if handlerType.NumIn() == 0 || handlerType.In(handlerType.NumIn()-1) != chunkStreamType {
+		log.Fatal("Chunked handler must take bodyrest.ChunkStream as its last parameter")
+	}
+
+	if handlerType.NumOut() != 1 || handlerType.Out(0) != errorType {
+		log.Fatal("Chunked handler must return exactly one error value")
+	}
+
+	cfg := newRouteConfig(opts)
+	plan := newBindPlan(handlerType, handlerType.NumIn()-1)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer recoverHandler(w, r)
+
+		finish, ok := admitRequest(w, r)
+		if !ok {
+			return
+		}
+		defer finish()
+
+		handlerArgsToCall, ok := bindArgs(w, r, cfg, plan)
+		if !ok {
+			return
+		}
+		defer releaseArgs(handlerArgsToCall)
+
+		rc := http.NewResponseController(w)
+		if r.ProtoMajor == 1 {
+			// HTTP/1.x servers close the request body once the response
+			// starts unless full duplex is enabled; HTTP/2 always allows it.
+			rc.EnableFullDuplex()
+		}
+
+		stream := &chunkStream{
+			w:        w,
+			rc:       rc,
+			ctx:      r.Context(),
+			dec:      json.NewDecoder(r.Body),
+			flushing: canFlush(w),
+		}
+
+		handlerArgsToCall = append(handlerArgsToCall, reflect.ValueOf(stream))
+		results := reflect.ValueOf(handlerFunc).Call(handlerArgsToCall)
+
+		err, _ := results[0].Interface().(error)
+		if err != nil && !errors.Is(err, context.Canceled) && !stream.hasStarted() {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("chunked stream ended with error: %v\n", err)
+		}
+
+		stream.finish()
+	})
+}
+
+type chunkStream struct {
+	mu       sync.Mutex
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	ctx      context.Context
+	dec      *json.Decoder
+	flushing bool
+	started  bool
+	buffered bytes.Buffer
+}
+
+func (s *chunkStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *chunkStream) Flushing() bool {
+	return s.flushing
+}
+
+func (s *chunkStream) Recv(v any) error {
+	return s.dec.Decode(v)
+}
+
+func (s *chunkStream) Send(v any) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	encoded, err := marshalJSON(v, false)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk: %w", err)
+	}
+	encoded = append(encoded, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.start()
+	if !s.flushing {
+		s.buffered.Write(encoded)
+		return nil
+	}
+
+	if _, err := s.w.Write(encoded); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+func (s *chunkStream) hasStarted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+// start writes the response headers before the first chunk.
+func (s *chunkStream) start() {
+	if s.started {
+		return
+	}
+
+	s.w.Header().Set("Content-Type", ndjsonContentType)
+	s.w.Header().Set("Cache-Control", "no-cache")
+	if s.flushing {
+		s.w.WriteHeader(http.StatusOK)
+	}
+	s.started = true
+}
+
+// finish writes the buffered chunks when flushing is not supported, or an
+// empty 200 when the handler sent nothing.
+func (s *chunkStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.start()
+	if !s.flushing {
+		s.w.WriteHeader(http.StatusOK)
+		s.w.Write(s.buffered.Bytes())
+	}
+}
+
+// canFlush reports whether w, or a writer it unwraps to, can flush, without
+// flushing it, as http.ResponseController would.
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		switch t := w.(type) {
+		case http.Flusher, interface{ FlushError() error }:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return false
+		}
+	}
+}
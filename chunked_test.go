@@ -0,0 +1,127 @@
+package bodyrest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errTestImportNotFound = errors.New("import not found")
+
+type testImportRow struct {
+	Name string `json:"name"`
+}
+
+type testImportProgress struct {
+	Done     int  `json:"done"`
+	Flushing bool `json:"flushing"`
+}
+
+func testImport(id int, stream ChunkStream) error {
+	if id == 0 {
+		return errTestImportNotFound
+	}
+
+	done := 0
+	for {
+		var row testImportRow
+		err := stream.Recv(&row)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		done++
+		if err := stream.Send(testImportProgress{Done: done, Flushing: stream.Flushing()}); err != nil {
+			return err
+		}
+	}
+}
+
+// nonFlushingWriter hides the Flush method of the recorder it wraps.
+type nonFlushingWriter struct {
+	w http.ResponseWriter
+}
+
+func (w nonFlushingWriter) Header() http.Header         { return w.w.Header() }
+func (w nonFlushingWriter) Write(b []byte) (int, error) { return w.w.Write(b) }
+func (w nonFlushingWriter) WriteHeader(status int)      { w.w.WriteHeader(status) }
+
+func TestHandleChunked(t *testing.T) {
+	RegisterError(errTestImportNotFound, http.StatusNotFound)
+
+	testCases := []struct {
+		name           string
+		path           string
+		body           string
+		noFlush        bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Streams a chunk per received row",
+			path:           "/imports/1",
+			body:           `{"name":"a"}` + "\n" + `{"name":"b"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"done":1,"flushing":true}` + "\n" + `{"done":2,"flushing":true}` + "\n",
+		},
+		{
+			name:           "Buffers chunks without flusher support",
+			path:           "/imports/1",
+			body:           `{"name":"a"}`,
+			noFlush:        true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"done":1,"flushing":false}` + "\n",
+		},
+		{
+			name:           "Error before first chunk",
+			path:           "/imports/0",
+			body:           `{"name":"a"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Something went wrong. Please try again later."}` + "\n",
+		},
+		{
+			name:           "Invalid path param",
+			path:           "/imports/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/imports/{id}", HandleChunked(testImport))
+			w := httptest.NewRecorder()
+			if tc.noFlush {
+				r.ServeHTTP(nonFlushingWriter{w: w}, req)
+			} else {
+				r.ServeHTTP(w, req)
+			}
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+
+			if tc.expectedStatus == http.StatusOK && w.Header().Get("Content-Type") != ndjsonContentType {
+				t.Errorf("Expected ndjson content type, got %s", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
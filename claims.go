@@ -0,0 +1,43 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ClaimsVerifierFunc verifies a bearer token and returns its claims.
+type ClaimsVerifierFunc[T any] func(ctx context.Context, token string) (T, error)
+
+// RegisterClaims lets handlers take a parameter of type T, e.g.
+// func(claims AuthClaims, req T), filled by verifying the bearer token of the
+// Authorization header with verify. Missing or invalid tokens are rejected
+// with 401 through the rest error handler.
+func RegisterClaims[T any](verify ClaimsVerifierFunc[T]) {
+	registerPrincipalType(typeOf[T]())
+	registerInjector(typeOf[T](), func(r *http.Request) (reflect.Value, error) {
+		token, ok := bearerToken(r)
+		if !ok {
+			return reflect.Value{}, errors.Join(ErrUnauthorized, errors.New("missing bearer token"))
+		}
+
+		claims, err := verify(r.Context(), token)
+		if err != nil {
+			return reflect.Value{}, errors.Join(ErrUnauthorized, err)
+		}
+
+		return reflect.ValueOf(&claims).Elem(), nil
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
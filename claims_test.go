@@ -0,0 +1,65 @@
+package bodyrest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testClaims struct {
+	Subject string
+}
+
+func testCreateUserAs(claims testClaims, u testUser) (testUser, error) {
+	return testUser{Name: claims.Subject + ":" + u.Name}, nil
+}
+
+func TestRegisterClaims(t *testing.T) {
+	RegisterClaims(func(ctx context.Context, token string) (testClaims, error) {
+		if token != "valid" {
+			return testClaims{}, errors.New("invalid token")
+		}
+		return testClaims{Subject: "admin"}, nil
+	})
+
+	testCases := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Valid token", authorization: "Bearer valid", expectedStatus: http.StatusOK, expectedBody: `{"name":"admin:john"}`},
+		{name: "Invalid token", authorization: "Bearer forged", expectedStatus: http.StatusUnauthorized, expectedBody: `{"message":"Something went wrong. Please try again later."}`},
+		{name: "Missing token", expectedStatus: http.StatusUnauthorized, expectedBody: `{"message":"Something went wrong. Please try again later."}`},
+		{name: "Other scheme", authorization: "Basic valid", expectedStatus: http.StatusUnauthorized, expectedBody: `{"message":"Something went wrong. Please try again later."}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"john"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", tc.authorization)
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(testCreateUserAs))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
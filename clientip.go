@@ -0,0 +1,104 @@
+package bodyrest
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strings"
+)
+
+// ClientIP can be taken as a handler parameter to receive the address of the
+// caller. Forwarding headers are only honored for requests coming from the
+// proxies set with SetTrustedProxies.
+type ClientIP string
+
+// UserAgent can be taken as a handler parameter to receive the User-Agent
+// header of the request.
+type UserAgent string
+
+var (
+	trustedProxies      []netip.Prefix
+	trustedProxyHeaders []string
+)
+
+// SetTrustedProxies sets the proxies, as CIDRs or single addresses, whose
+// forwarding headers are used to resolve ClientIP, and the headers to read,
+// e.g. "X-Forwarded-For" or "X-Real-IP". Headers are checked in order.
+func SetTrustedProxies(proxies []string, headers ...string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	trustedProxies = prefixes
+	trustedProxyHeaders = headers
+	return nil
+}
+
+func isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resolveClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	remote, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(remote) {
+		return host
+	}
+
+	for _, header := range trustedProxyHeaders {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+
+		hops := strings.Split(strings.Join(values, ","), ",")
+		// Walk from the nearest hop, skipping trusted proxies, so a client
+		// cannot spoof its address by prepending entries.
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			if !isTrustedProxy(hop) || i == 0 {
+				return hop.String()
+			}
+		}
+	}
+
+	return host
+}
+
+func init() {
+	registerInjector(typeOf[ClientIP](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(ClientIP(resolveClientIP(r))), nil
+	})
+
+	registerInjector(typeOf[UserAgent](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(UserAgent(r.UserAgent())), nil
+	})
+}
@@ -0,0 +1,41 @@
+package bodyrest
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	if err := SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}, "X-Forwarded-For", "X-Real-IP"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetTrustedProxies(nil)
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expectedIP string
+	}{
+		{name: "Direct client", remoteAddr: "203.0.113.7:1234", expectedIP: "203.0.113.7"},
+		{name: "Untrusted peer headers are ignored", remoteAddr: "203.0.113.7:1234", headers: map[string]string{"X-Forwarded-For": "1.2.3.4"}, expectedIP: "203.0.113.7"},
+		{name: "Trusted proxy", remoteAddr: "10.1.2.3:80", headers: map[string]string{"X-Forwarded-For": "198.51.100.2"}, expectedIP: "198.51.100.2"},
+		{name: "Spoofed entries are skipped", remoteAddr: "10.1.2.3:80", headers: map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.2, 10.0.0.5"}, expectedIP: "198.51.100.2"},
+		{name: "Fallback header", remoteAddr: "192.168.1.1:80", headers: map[string]string{"X-Real-IP": "198.51.100.9"}, expectedIP: "198.51.100.9"},
+		{name: "Trusted proxy without headers", remoteAddr: "10.1.2.3:80", expectedIP: "10.1.2.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			if ip := resolveClientIP(req); ip != tc.expectedIP {
+				t.Errorf("Expected client IP %s, got %s", tc.expectedIP, ip)
+			}
+		})
+	}
+}
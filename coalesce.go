@@ -0,0 +1,106 @@
+package bodyrest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// errCoalescedPanic is returned to requests coalesced with one whose handler
+// panicked.
+var errCoalescedPanic = errors.New("coalesced handler call panicked")
+
+// WithCoalescing deduplicates concurrent GET requests of the route with the
+// same bound parameters: the handler runs once and every waiting request is
+// answered with its results. Other methods are not coalesced. Params are
+// compared by value, all their fields included, injected ones such as
+// claims or url.Values too; provided params are left out. Handlers taking
+// params that cannot be compared, such as *LazyForm, or returning an
+// http.Handler, whose work the returned handler does, cannot be coalesced.
+// Results are shared between the requests and must not be modified by
+// wrappers.
+func WithCoalescing() Option {
+	return func(cfg *routeConfig) {
+		cfg.coalescing = true
+	}
+}
+
+// coalesce runs the concurrent GET calls of next with the same coalesceKey
+// once.
+func coalesce(plan *bindPlan) func(next Invoker) Invoker {
+	group := &flightGroup{calls: map[string]*flightCall{}}
+	return func(next Invoker) Invoker {
+		return func(r *http.Request, args []any) ([]any, error) {
+			if r.Method != http.MethodGet {
+				return next(r, args)
+			}
+
+			key, err := coalesceKey(r, plan, args)
+			if err != nil {
+				return next(r, args)
+			}
+
+			return group.do(key, func() ([]any, error) {
+				return next(r, args)
+			})
+		}
+	}
+}
+
+// coalesceKey keys a call by its route and the values of its params bound
+// or injected from the request, see writeParamsKey.
+func coalesceKey(r *http.Request, plan *bindPlan, args []any) (string, error) {
+	values := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		values[i] = reflect.ValueOf(arg)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(routePattern(r))
+	buf.WriteByte('\n')
+	if err := writeParamsKey(&buf, plan, values); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done    chan struct{}
+	results []any
+	err     error
+}
+
+// do runs fn once for all concurrent callers with the same key.
+func (g *flightGroup) do(key string, fn func() ([]any, error)) ([]any, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.results, c.err
+	}
+
+	c := &flightCall{done: make(chan struct{}), err: errCoalescedPanic}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.results, c.err = fn()
+	return c.results, c.err
+}
@@ -0,0 +1,188 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestWithCoalescing(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	r := chi.NewRouter()
+	r.Get("/orgs/{org}/users/{id}", HandleTo(func(org string, id int) (map[string]any, error) {
+		calls.Add(1)
+		<-release
+		return map[string]any{"org": org, "id": id}, nil
+	}, WithCoalescing()))
+
+	testCases := []struct {
+		name          string
+		urls          []string
+		expectedCalls int32
+	}{
+		{name: "identical requests", urls: []string{"/orgs/acme/users/1", "/orgs/acme/users/1", "/orgs/acme/users/1"}, expectedCalls: 1},
+		{name: "different params", urls: []string{"/orgs/acme/users/1", "/orgs/acme/users/2"}, expectedCalls: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls.Store(0)
+			release = make(chan struct{})
+
+			bodies := make([]string, len(tc.urls))
+			var wg sync.WaitGroup
+			for i, url := range tc.urls {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					w := httptest.NewRecorder()
+					r.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+					if w.Code != http.StatusOK {
+						t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+					}
+					bodies[i] = strings.TrimSpace(w.Body.String())
+				}()
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls.Load() != tc.expectedCalls {
+				t.Errorf("Expected %d handler calls, got %d", tc.expectedCalls, calls.Load())
+			}
+
+			for i, url := range tc.urls {
+				expected := `{"id":` + url[len(url)-1:] + `,"org":"acme"}`
+				if strings.Join(strings.Fields(bodies[i]), "") != expected {
+					t.Errorf("Expected body %s, got %s", expected, bodies[i])
+				}
+			}
+		})
+	}
+}
+
+type testTenantClaims struct {
+	tenant string
+}
+
+func TestCoalescingKeysPrincipals(t *testing.T) {
+	RegisterClaims(func(ctx context.Context, token string) (testTenantClaims, error) {
+		return testTenantClaims{tenant: token}, nil
+	})
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	handler := HandleTo(func(claims testTenantClaims, id int) (string, error) {
+		calls.Add(1)
+		<-release
+		return claims.tenant, nil
+	}, WithCoalescing())
+
+	r := chi.NewRouter()
+	r.Get("/users/{id}", handler)
+
+	tokens := []string{"acme", "globex"}
+	bodies := make([]string, len(tokens))
+	var wg sync.WaitGroup
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/users/1", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			bodies[i] = strings.TrimSpace(w.Body.String())
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 2 {
+		t.Errorf("Expected %d handler calls, got %d", 2, calls.Load())
+	}
+	for i, token := range tokens {
+		if expected := `"` + token + `"`; bodies[i] != expected {
+			t.Errorf("Expected body %s, got %s", expected, bodies[i])
+		}
+	}
+}
+
+type testMeQuery struct {
+	User string `query:"user" json:"-"`
+}
+
+func TestCoalescingKeysQuery(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler func(calls *atomic.Int32, release chan struct{}) interface{}
+	}{
+		{
+			name: "injected url.Values",
+			handler: func(calls *atomic.Int32, release chan struct{}) interface{} {
+				return func(q url.Values) (string, error) {
+					calls.Add(1)
+					<-release
+					return q.Get("user"), nil
+				}
+			},
+		},
+		{
+			name: "query field hidden from JSON",
+			handler: func(calls *atomic.Int32, release chan struct{}) interface{} {
+				return func(q testMeQuery) (string, error) {
+					calls.Add(1)
+					<-release
+					return q.User, nil
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			release := make(chan struct{})
+			handler := HandleTo(tc.handler(&calls, release), WithCoalescing())
+
+			users := []string{"alice", "bob"}
+			bodies := make([]string, len(users))
+			var wg sync.WaitGroup
+			for i, user := range users {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, httptest.NewRequest("GET", "/me?user="+user, nil))
+					bodies[i] = strings.TrimSpace(w.Body.String())
+				}()
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls.Load() != 2 {
+				t.Errorf("Expected %d handler calls, got %d", 2, calls.Load())
+			}
+			for i, user := range users {
+				if expected := `"` + user + `"`; bodies[i] != expected {
+					t.Errorf("Expected body %s, got %s", expected, bodies[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package bodyrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var compressionThreshold = 1024
+
+// SetCompressionThreshold sets the minimum size in bytes of an auto-encoded
+// response body before it is gzipped for clients that accept it. A negative
+// threshold disables compression.
+func SetCompressionThreshold(size int) {
+	compressionThreshold = size
+}
+
+// compressPayload gzips payload when the request accepts gzip and the payload
+// is large enough, setting the related headers on w.
+func compressPayload(w http.ResponseWriter, r *http.Request, payload []byte) []byte {
+	if compressionThreshold < 0 {
+		return payload
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	if len(payload) < compressionThreshold || !acceptsGzip(r) {
+		return payload
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return payload
+	}
+	if err := zw.Close(); err != nil {
+		return payload
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	return buf.Bytes()
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				return err == nil && q > 0
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package bodyrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCompressedResponses(t *testing.T) {
+	longName := strings.Repeat("a", 2048)
+
+	testCases := []struct {
+		name               string
+		acceptEncoding     string
+		userName           string
+		expectedCompressed bool
+	}{
+		{name: "Large body with gzip accepted", acceptEncoding: "gzip, deflate", userName: longName, expectedCompressed: true},
+		{name: "Large body with gzip refused", acceptEncoding: "gzip;q=0", userName: longName, expectedCompressed: false},
+		{name: "Large body without gzip", acceptEncoding: "", userName: longName, expectedCompressed: false},
+		{name: "Small body with gzip accepted", acceptEncoding: "gzip", userName: "john", expectedCompressed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"`+tc.userName+`"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(testCreateUser))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Expected Vary header, got %q", w.Header().Get("Vary"))
+			}
+
+			compressed := w.Header().Get("Content-Encoding") == "gzip"
+			if compressed != tc.expectedCompressed {
+				t.Fatalf("Expected compressed %v, got %v", tc.expectedCompressed, compressed)
+			}
+
+			body := w.Body.Bytes()
+			if compressed {
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, err = io.ReadAll(zr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expectedBody := `{"name":"` + tc.userName + `"}`
+			if strings.TrimSpace(string(body)) != expectedBody {
+				t.Errorf("Expected body %s, got %s", expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package bodyrest
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// WithMaxConcurrent bounds the number of simultaneous requests handled by
+// the route's handler to n. Requests over the limit wait up to wait for a
+// slot, after binding, and are then rejected with ErrServiceUnavailable
+// (503). A slot is held until the response is written, so it also covers
+// the work of an http.Handler returned by the handler.
+func WithMaxConcurrent(n int, wait time.Duration) Option {
+	if n <= 0 {
+		log.Fatalf("max concurrent requests must be positive, got %d", n)
+	}
+
+	return func(cfg *routeConfig) {
+		slots := make(chan struct{}, n)
+		cfg.wrappers = append(cfg.wrappers, func(next Invoker) Invoker {
+			return func(r *http.Request, args []any) ([]any, error) {
+				if err := acquireSlot(r, slots, wait); err != nil {
+					return nil, err
+				}
+				afterResponse(r, func() { <-slots })
+
+				return next(r, args)
+			}
+		})
+	}
+}
+
+func acquireSlot(r *http.Request, slots chan struct{}, wait time.Duration) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w: %d requests in flight", ErrServiceUnavailable, cap(slots))
+	case <-r.Context().Done():
+		return r.Context().Err()
+	}
+}
@@ -0,0 +1,92 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithMaxConcurrent(t *testing.T) {
+	testCases := []struct {
+		name             string
+		wait             time.Duration
+		expectedStatuses map[int]int
+	}{
+		{name: "over limit rejected", wait: 10 * time.Millisecond, expectedStatuses: map[int]int{http.StatusOK: 1, http.StatusServiceUnavailable: 1}},
+		{name: "queued until free", wait: time.Second, expectedStatuses: map[int]int{http.StatusOK: 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{}, 2)
+			release := make(chan struct{})
+			handler := HandleTo(func() (string, error) {
+				started <- struct{}{}
+				<-release
+				return "done", nil
+			}, WithMaxConcurrent(1, tc.wait))
+
+			codes := make(chan int, 2)
+			var wg sync.WaitGroup
+			serve := func() {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest("GET", "/reports", nil))
+				codes <- w.Code
+			}
+
+			wg.Add(2)
+			go serve()
+			<-started
+			go serve()
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(codes)
+
+			statuses := map[int]int{}
+			for code := range codes {
+				statuses[code]++
+			}
+			for status, count := range tc.expectedStatuses {
+				if statuses[status] != count {
+					t.Errorf("Expected %d responses with status code %d, got %v", count, status, statuses)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMaxConcurrentHandlerResult(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	handler := HandleTo(func() http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.Write([]byte("done"))
+		}
+	}, WithMaxConcurrent(1, 10*time.Millisecond))
+
+	codes := make(chan int, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/reports", nil))
+		codes <- w.Code
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/reports", nil))
+	close(release)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if code := <-codes; code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, code)
+	}
+}
@@ -0,0 +1,369 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// ContractCase is a generated request against a route together with the
+// status bodyrest must answer it with. An ExpectedStatus of 0 means any
+// status but 400, for requests that must bind.
+type ContractCase struct {
+	Name           string
+	Method         string
+	Path           string
+	Body           string
+	ExpectedStatus int
+}
+
+// ContractCases derives test requests from the routes of rt: for every route
+// taking a body, a valid payload (the first example, or one synthesized from
+// the request struct), one payload per missing required string, slice, map
+// or pointer field and one per field of the wrong JSON type.
+func ContractCases(rt *Router) []ContractCase {
+	var cases []ContractCase
+	for _, route := range rt.Routes() {
+		path := samplePath(route)
+		bodyType, ok := requestBodyType(route.HandlerType)
+		if !ok {
+			cases = append(cases, ContractCase{
+				Name:   route.Method + " " + route.Pattern,
+				Method: route.Method,
+				Path:   path,
+			})
+			continue
+		}
+
+		valid := map[string]any{}
+		if len(route.Examples) > 0 && route.Examples[0].Request != nil {
+			valid = examplePayload(route.Examples[0].Request)
+		} else {
+			fillSample(bodyType, valid)
+		}
+
+		name := route.Method + " " + route.Pattern
+		cases = append(cases, ContractCase{
+			Name:   name + " valid",
+			Method: route.Method,
+			Path:   path,
+			Body:   encodeSample(valid),
+		})
+
+		for _, field := range bodyFields(bodyType) {
+			key := jsonFieldName(field)
+
+			if isFieldRequired(field) && canDetectMissing(field.Type) {
+				cases = append(cases, ContractCase{
+					Name:           name + " missing " + key,
+					Method:         route.Method,
+					Path:           path,
+					Body:           encodeSample(withoutKey(valid, key)),
+					ExpectedStatus: http.StatusBadRequest,
+				})
+			}
+
+			if wrong, ok := wrongTypeSample(field.Type); ok {
+				cases = append(cases, ContractCase{
+					Name:           name + " wrong type " + key,
+					Method:         route.Method,
+					Path:           path,
+					Body:           encodeSample(withKey(valid, key, wrong)),
+					ExpectedStatus: http.StatusBadRequest,
+				})
+			}
+		}
+	}
+
+	return cases
+}
+
+// WriteContractTests writes a table-driven Go test file for package pkg
+// running cases. The file expects the package to provide
+// func newContractRouter() http.Handler returning the service's router.
+func WriteContractTests(w io.Writer, pkg string, cases []ContractCase) error {
+	var buf bytes.Buffer
+	if err := contractTemplate.Execute(&buf, struct {
+		Package string
+		Cases   []ContractCase
+	}{pkg, cases}); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+var contractTemplate = template.Must(template.New("contract").Funcs(template.FuncMap{
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}).Parse(`// Code generated by bodyrest.WriteContractTests. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+{{- range .Cases}}
+		{
+			name:           {{quote .Name}},
+			method:         {{quote .Method}},
+			path:           {{quote .Path}},
+			body:           {{quote .Body}},
+			expectedStatus: {{.ExpectedStatus}},
+		},
+{{- end}}
+	}
+
+	handler := newContractRouter()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if tc.expectedStatus == 0 && w.Code == http.StatusBadRequest {
+				t.Errorf("Expected the request to bind, got status code %d", w.Code)
+			}
+
+			if tc.expectedStatus != 0 && w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+`))
+
+// requestBodyType returns the struct parameter of handlerType decoded from
+// the body, if any.
+func requestBodyType(handlerType reflect.Type) (reflect.Type, bool) {
+	if handlerType == nil {
+		return nil, false
+	}
+
+	for _, param := range newBindPlan(handlerType, handlerType.NumIn()).paramPlans() {
+		if param.kind == paramStruct && param.hasBody {
+			return param.typ, true
+		}
+	}
+
+	return nil, false
+}
+
+// samplePath fills the placeholders of the route pattern with values that
+// convert to the types of the handler's path params and match the regexp
+// constraints of the placeholders.
+func samplePath(route RouteInfo) string {
+	var kinds []reflect.Kind
+	if route.HandlerType != nil {
+		for _, param := range newBindPlan(route.HandlerType, route.HandlerType.NumIn()).paramPlans() {
+			if param.kind == paramPath {
+				kinds = append(kinds, param.typ.Kind())
+			}
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	for i, p := range parsePlaceholders(route.Pattern) {
+		sample := "1"
+		if i < len(kinds) {
+			switch kinds[i] {
+			case reflect.Bool:
+				sample = "true"
+			case reflect.String:
+				sample = "example"
+			}
+		}
+		if p.constraint != "" {
+			sample = constrainedSample(p.constraint, sample)
+		}
+
+		b.WriteString(route.Pattern[last:p.start])
+		b.WriteString(sample)
+		last = p.end
+	}
+	b.WriteString(route.Pattern[last:])
+
+	return b.String()
+}
+
+// pathSamples are tried in order for placeholders whose regexp constraint
+// the sample of their type does not match.
+var pathSamples = []string{"1", "example", "true", "2024-01-02", "a", "A", "0", "1.5", "00000000-0000-0000-0000-000000000000"}
+
+// constrainedSample returns sample if it matches constraint, as chi anchors
+// it, or else the first of pathSamples that does. It falls back to sample
+// when the constraint does not compile or nothing matches.
+func constrainedSample(constraint, sample string) string {
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil || re.MatchString(sample) {
+		return sample
+	}
+
+	for _, candidate := range pathSamples {
+		if re.MatchString(candidate) {
+			return candidate
+		}
+	}
+
+	return sample
+}
+
+// bodyFields returns the fields of t decoded from the body, with embedded
+// structs promoted.
+func bodyFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			fields = append(fields, bodyFields(field.Type)...)
+			continue
+		}
+
+		if _, _, ok := sourceTagOf(field); !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func fillSample(t reflect.Type, obj map[string]any) {
+	for _, field := range bodyFields(t) {
+		obj[jsonFieldName(field)] = sampleValue(field.Type, 0)
+	}
+}
+
+func sampleValue(t reflect.Type, depth int) any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if depth > 8 || isUnmarshaler(t) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "example"
+	case reflect.Bool:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 1
+	case reflect.Slice, reflect.Array:
+		return []any{sampleValue(t.Elem(), depth+1)}
+	case reflect.Map:
+		return map[string]any{"key": sampleValue(t.Elem(), depth+1)}
+	case reflect.Struct:
+		obj := map[string]any{}
+		for _, field := range bodyFields(t) {
+			obj[jsonFieldName(field)] = sampleValue(field.Type, depth+1)
+		}
+		return obj
+	default:
+		return nil
+	}
+}
+
+// canDetectMissing reports whether validation can tell a missing field of
+// type t from a sent one; numbers and booleans decode to valid zero values.
+func canDetectMissing(t reflect.Type) bool {
+	if isUnmarshaler(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// wrongTypeSample returns a JSON value of a type t cannot be decoded from.
+func wrongTypeSample(t reflect.Type) (any, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if isUnmarshaler(t) {
+		return nil, false
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return 1, true
+	case reflect.Bool, reflect.Slice, reflect.Array, reflect.Map, reflect.Struct,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "example", true
+	default:
+		return nil, false
+	}
+}
+
+func examplePayload(example any) map[string]any {
+	obj := map[string]any{}
+	if data, err := json.Marshal(example); err == nil {
+		json.Unmarshal(data, &obj)
+	}
+
+	return obj
+}
+
+func withoutKey(obj map[string]any, key string) map[string]any {
+	out := make(map[string]any, len(obj))
+	for k, v := range obj {
+		if k != key {
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+func withKey(obj map[string]any, key string, value any) map[string]any {
+	out := withoutKey(obj, key)
+	out[key] = value
+	return out
+}
+
+func encodeSample(obj map[string]any) string {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(data)
+}
@@ -0,0 +1,88 @@
+package bodyrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testOrder struct {
+	Item     string   `json:"item"`
+	Quantity int      `json:"quantity"`
+	Gift     bool     `json:"gift,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func testCreateOrder(org string, o testOrder) (testOrder, error) {
+	return o, nil
+}
+
+func newTestContractRouter() *Router {
+	rt := NewRouter(nil)
+	rt.Post("/users", testCreateUser, WithExample(testUser{Name: "John"}, nil))
+	rt.Post("/orgs/{org}/orders", testCreateOrder)
+	rt.Get("/orgs/{org}/users/{id}", testGetOrgUser)
+	return rt
+}
+
+func TestContractCases(t *testing.T) {
+	rt := newTestContractRouter()
+	cases := ContractCases(rt)
+
+	expectedNames := []string{
+		"POST /users valid",
+		"POST /users missing name",
+		"POST /users wrong type name",
+		"POST /orgs/{org}/orders valid",
+		"POST /orgs/{org}/orders missing item",
+		"POST /orgs/{org}/orders wrong type item",
+		"POST /orgs/{org}/orders wrong type quantity",
+		"POST /orgs/{org}/orders wrong type gift",
+		"POST /orgs/{org}/orders wrong type tags",
+		"GET /orgs/{org}/users/{id}",
+	}
+	if len(cases) != len(expectedNames) {
+		t.Fatalf("Expected %d cases, got %d: %+v", len(expectedNames), len(cases), cases)
+	}
+
+	for i, tc := range cases {
+		if tc.Name != expectedNames[i] {
+			t.Errorf("Expected case %q, got %q", expectedNames[i], tc.Name)
+		}
+
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.Body))
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if tc.ExpectedStatus == 0 && w.Code == http.StatusBadRequest {
+				t.Errorf("Expected the request to bind, got status code %d", w.Code)
+			}
+
+			if tc.ExpectedStatus != 0 && w.Code != tc.ExpectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.ExpectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestWriteContractTests(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteContractTests(&buf, "api", ContractCases(newTestContractRouter())); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"package api",
+		"func TestContract(t *testing.T) {",
+		`name:           "POST /users missing name",`,
+		`body:           "{\"name\":1}",`,
+		"handler := newContractRouter()",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected generated tests to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+}
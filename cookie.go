@@ -0,0 +1,34 @@
+package bodyrest
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Cookies can be taken as a handler parameter to receive the request
+// cookies by name. When a name is repeated the first cookie wins, as with
+// http.Request.Cookie.
+type Cookies map[string]string
+
+func init() {
+	registerInjector(typeOf[Cookies](), func(r *http.Request) (reflect.Value, error) {
+		cookies := Cookies{}
+		for _, cookie := range r.Cookies() {
+			if _, ok := cookies[cookie.Name]; !ok {
+				cookies[cookie.Name] = cookie.Value
+			}
+		}
+
+		return reflect.ValueOf(cookies), nil
+	})
+}
+
+// cookieValue returns the value of the named cookie of r, if sent.
+func cookieValue(r *http.Request, name string) (string, bool) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", false
+	}
+
+	return cookie.Value, true
+}
@@ -0,0 +1,50 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testSession struct {
+	ID      string `cookie:"session_id"`
+	Version int    `cookie:"version"`
+}
+
+func TestBindCookies(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cookies        []*http.Cookie
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "typed cookies", cookies: []*http.Cookie{{Name: "session_id", Value: "abc"}, {Name: "version", Value: "2"}}, expectedStatus: http.StatusOK, expectedBody: `"abc:2:abc"`},
+		{name: "missing cookies", expectedStatus: http.StatusOK, expectedBody: `":0:"`},
+		{name: "invalid cookie", cookies: []*http.Cookie{{Name: "version", Value: "two"}}, expectedStatus: http.StatusBadRequest},
+	}
+
+	handler := HandleTo(func(cookies Cookies, s testSession) (string, error) {
+		return s.ID + ":" + strconv.Itoa(s.Version) + ":" + cookies["session_id"], nil
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/session", nil)
+			for _, cookie := range tc.cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
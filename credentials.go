@@ -0,0 +1,59 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// BasicCredentials can be taken as a handler parameter to receive the
+// credentials of the Basic Authorization header. Requests without them are
+// rejected with 401.
+type BasicCredentials struct {
+	Username string
+	Password string
+}
+
+// APIKey can be taken as a handler parameter to receive the API key of the
+// request, read from the header or query parameter set with SetAPIKeySource.
+// Requests without a key are rejected with 401.
+type APIKey string
+
+var (
+	apiKeyHeader = "X-API-Key"
+	apiKeyQuery  = ""
+)
+
+// SetAPIKeySource sets where APIKey parameters are read from. The header is
+// checked first; an empty name disables that source. By default the key is
+// read from the X-API-Key header only.
+func SetAPIKeySource(header, query string) {
+	apiKeyHeader = header
+	apiKeyQuery = query
+}
+
+func init() {
+	registerInjector(typeOf[BasicCredentials](), func(r *http.Request) (reflect.Value, error) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return reflect.Value{}, errors.Join(ErrUnauthorized, errors.New("missing basic credentials"))
+		}
+
+		return reflect.ValueOf(BasicCredentials{Username: username, Password: password}), nil
+	})
+
+	registerInjector(typeOf[APIKey](), func(r *http.Request) (reflect.Value, error) {
+		var key string
+		if apiKeyHeader != "" {
+			key = r.Header.Get(apiKeyHeader)
+		}
+		if key == "" && apiKeyQuery != "" {
+			key = r.URL.Query().Get(apiKeyQuery)
+		}
+		if key == "" {
+			return reflect.Value{}, errors.Join(ErrUnauthorized, errors.New("missing API key"))
+		}
+
+		return reflect.ValueOf(APIKey(key)), nil
+	})
+}
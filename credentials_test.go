@@ -0,0 +1,94 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCredentialParameters(t *testing.T) {
+	SetAPIKeySource("X-API-Key", "api_key")
+	defer SetAPIKeySource("X-API-Key", "")
+
+	basicHandler := func(id int, creds BasicCredentials) (string, error) {
+		return creds.Username + ":" + creds.Password, nil
+	}
+	keyHandler := func(key APIKey) (string, error) {
+		return string(key), nil
+	}
+
+	testCases := []struct {
+		name           string
+		handler        interface{}
+		path           string
+		setup          func(req *http.Request)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Basic credentials",
+			handler:        basicHandler,
+			path:           "/test/1",
+			setup:          func(req *http.Request) { req.SetBasicAuth("john", "secret") },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"john:secret"`,
+		},
+		{
+			name:           "Missing basic credentials",
+			handler:        basicHandler,
+			path:           "/test/1",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"message":"Something went wrong. Please try again later."}`,
+		},
+		{
+			name:           "API key from header",
+			handler:        keyHandler,
+			path:           "/test/1",
+			setup:          func(req *http.Request) { req.Header.Set("X-API-Key", "k1") },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"k1"`,
+		},
+		{
+			name:           "API key from query",
+			handler:        keyHandler,
+			path:           "/test/1?api_key=k2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"k2"`,
+		},
+		{
+			name:           "Missing API key",
+			handler:        keyHandler,
+			path:           "/test/1",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"message":"Something went wrong. Please try again later."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.setup != nil {
+				tc.setup(req)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/test/{id}", HandleTo(tc.handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
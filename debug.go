@@ -0,0 +1,177 @@
+package bodyrest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// DebugRoute describes a registered route as rendered by DebugRoutes.
+type DebugRoute struct {
+	Method       string       `json:"method"`
+	Pattern      string       `json:"pattern"`
+	Placeholders []string     `json:"placeholders,omitempty"`
+	Params       []DebugParam `json:"params,omitempty"`
+	// Body maps the JSON names of the fields decoded from the body to their
+	// Go types.
+	Body map[string]string `json:"body,omitempty"`
+	// Mismatches lists what would make the route fail at request time.
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+// DebugParam is a handler parameter and where it is bound from: path, body,
+// params (a struct bound from tagged fields only), multipart, injected or
+// provided.
+type DebugParam struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+// DebugRoutes returns a handler rendering every route registered on rt with
+// its placeholders, bound parameter types, body schema and the mismatches
+// detected between them, to catch misconfigured routes before they fail
+// requests. It exposes handler internals and is meant for development only.
+func DebugRoutes(rt *Router) http.HandlerFunc {
+	cfg := newRouteConfig(nil)
+	return func(w http.ResponseWriter, r *http.Request) {
+		routes := rt.Routes()
+		debug := make([]DebugRoute, len(routes))
+		for i, route := range routes {
+			debug[i] = debugRoute(route)
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		writeResponse(w, r, cfg, Response{Status: http.StatusOK, Body: debug})
+	}
+}
+
+func debugRoute(route RouteInfo) DebugRoute {
+	debug := DebugRoute{
+		Method:       route.Method,
+		Pattern:      route.Pattern,
+		Placeholders: routePlaceholders(route.Pattern),
+		Mismatches:   routeMismatches(route),
+	}
+	if route.HandlerType == nil {
+		return debug
+	}
+
+	for _, param := range newBindPlan(route.HandlerType, route.HandlerType.NumIn()).paramPlans() {
+		var source string
+		switch param.kind {
+		case paramInjected:
+			source = "injected"
+		case paramProvided:
+			source = "provided"
+		case paramMultipart:
+			source = "multipart"
+		case paramStruct, paramExtraStruct:
+			source = "params"
+			if param.hasBody {
+				source = "body"
+			}
+		case paramPath:
+			source = "path"
+		}
+		debug.Params = append(debug.Params, DebugParam{Type: param.typ.String(), Source: source})
+	}
+
+	if bodyType, ok := requestBodyType(route.HandlerType); ok {
+		debug.Body = map[string]string{}
+		for _, field := range bodyFields(bodyType) {
+			debug.Body[jsonFieldName(field)] = field.Type.String()
+		}
+	}
+
+	return debug
+}
+
+// routeMismatches returns the problems found between the pattern of route
+// and the parameters of its handler.
+func routeMismatches(route RouteInfo) []string {
+	handlerType := route.HandlerType
+	if handlerType == nil || handlerType.Kind() != reflect.Func {
+		return nil
+	}
+
+	var mismatches []string
+
+	positional := 0
+	for _, param := range newBindPlan(handlerType, handlerType.NumIn()).paramPlans() {
+		switch param.kind {
+		case paramStruct:
+			mismatches = append(mismatches, sourceFieldMismatches(param.typ)...)
+		case paramExtraStruct:
+			mismatches = append(mismatches, fmt.Sprintf("more than one body struct: %s", param.typ))
+		case paramPath:
+			if !isPathParamType(param.typ) {
+				mismatches = append(mismatches, fmt.Sprintf("path param %d has unsupported type %s", positional, param.typ))
+			}
+			positional++
+		}
+	}
+
+	if placeholders := routePlaceholders(route.Pattern); positional > len(placeholders) {
+		mismatches = append(mismatches, fmt.Sprintf("handler takes %d path params, pattern has %d placeholders", positional, len(placeholders)))
+	}
+
+	if err := checkPathParams(route.Pattern, handlerType); err != nil {
+		mismatches = append(mismatches, err.Error())
+	}
+
+	if err := checkProvidedParams(handlerType); err != nil {
+		mismatches = append(mismatches, err.Error())
+	}
+
+	return mismatches
+}
+
+// sourceFieldMismatches returns the fields of t bound from a source tag with
+// a type the values of the source cannot be parsed into.
+func sourceFieldMismatches(t reflect.Type) []string {
+	var mismatches []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			mismatches = append(mismatches, sourceFieldMismatches(field.Type)...)
+			continue
+		}
+
+		source, name, ok := sourceTagOf(field)
+		if !ok {
+			continue
+		}
+
+		typ := field.Type
+		if typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if !isPathParamType(typ) {
+			mismatches = append(mismatches, fmt.Sprintf("%s param %q has unsupported type %s", source, name, field.Type))
+		}
+	}
+
+	return mismatches
+}
+
+// isPathParamType reports whether a path param of type t can be parsed by
+// setFieldFromString.
+func isPathParamType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
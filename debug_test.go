@@ -0,0 +1,57 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDebugRoutes(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Post("/users", testCreateUser)
+	rt.Get("/orgs/{org}/repos/{repo}/issues/{num}", testGetIssue)
+	rt.Get("/users/{id}", func(org string, id []int) (int, error) { return 0, nil })
+
+	req := httptest.NewRequest("GET", "/debug/routes", nil)
+	w := httptest.NewRecorder()
+	DebugRoutes(rt).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var routes []DebugRoute
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DebugRoute{
+		{
+			Method:  http.MethodPost,
+			Pattern: "/users",
+			Params:  []DebugParam{{Type: "bodyrest.testUser", Source: "body"}},
+			Body:    map[string]string{"name": "string"},
+		},
+		{
+			Method:       http.MethodGet,
+			Pattern:      "/orgs/{org}/repos/{repo}/issues/{num}",
+			Placeholders: []string{"org", "repo", "num"},
+			Params:       []DebugParam{{Type: "bodyrest.testIssueRef", Source: "params"}},
+		},
+		{
+			Method:       http.MethodGet,
+			Pattern:      "/users/{id}",
+			Placeholders: []string{"id"},
+			Params:       []DebugParam{{Type: "string", Source: "path"}, {Type: "[]int", Source: "path"}},
+			Mismatches: []string{
+				"path param 1 has unsupported type []int",
+				"handler takes 2 path params, pattern has 1 placeholders",
+			},
+		},
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, routes)
+	}
+}
@@ -0,0 +1,90 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeError describes why a request body could not be decoded. The rest
+// error handler can read it with errors.As on ErrorFromRequest to tell the
+// client which field was wrong.
+type DecodeError struct {
+	// Field is the dotted JSON path of the offending field, empty for
+	// syntax errors.
+	Field string
+	// Expected and Got are JSON type names such as "number" or "string".
+	Expected string
+	Got      string
+	// Offset is the byte offset in the body where decoding failed.
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Field == "" && e.Expected == "" {
+		return fmt.Sprintf("invalid JSON at offset %d: %v", e.Offset, e.Err)
+	}
+
+	if e.Field == "" {
+		return fmt.Sprintf("expected %s, got %s", e.Expected, e.Got)
+	}
+
+	return fmt.Sprintf("%s: expected %s, got %s", e.Field, e.Expected, e.Got)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError wraps the errors of encoding/json into a DecodeError. The
+// value that failed is never included, only its JSON type.
+func newDecodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		got, _, _ := strings.Cut(typeErr.Value, " ")
+		return &DecodeError{
+			Field:    typeErr.Field,
+			Expected: jsonTypeName(typeErr.Type),
+			Got:      got,
+			Offset:   typeErr.Offset,
+			Err:      err,
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &DecodeError{Offset: syntaxErr.Offset, Err: err}
+	}
+
+	return err
+}
+
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return t.String()
+	}
+}
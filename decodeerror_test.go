@@ -0,0 +1,92 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDecodeError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		message  string
+		field    string
+		expected string
+		got      string
+	}{
+		{
+			name:     "type mismatch",
+			body:     `{"code":"abc","name":"x"}`,
+			message:  "code: expected number, got string",
+			field:    "code",
+			expected: "number",
+			got:      "string",
+		},
+		{
+			name:     "nested field",
+			body:     `{"code":1,"name":"x","address":{"zip":true}}`,
+			message:  "address.zip: expected string, got bool",
+			field:    "address.zip",
+			expected: "string",
+			got:      "bool",
+		},
+		{
+			name:    "syntax error",
+			body:    `{"code":1,}`,
+			message: "invalid JSON at offset 11",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reported error
+			saved := restErrorFunc
+			restErrorFunc = func(w http.ResponseWriter, r *http.Request, status int) {
+				reported = ErrorFromRequest(r)
+				w.WriteHeader(status)
+			}
+			defer func() { restErrorFunc = saved }()
+
+			req := httptest.NewRequest("POST", "/codes", strings.NewReader(tc.body))
+
+			r := chi.NewRouter()
+			r.Post("/codes", HandleTo(func(req struct {
+				Code    int    `json:"code"`
+				Name    string `json:"name"`
+				Address struct {
+					Zip string `json:"zip"`
+				} `json:"address,omitempty"`
+			}) (int, any, error) {
+				return http.StatusNoContent, nil, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(reported, &decodeErr) {
+				t.Fatalf("Expected DecodeError, got %v", reported)
+			}
+
+			if !strings.HasPrefix(decodeErr.Error(), tc.message) {
+				t.Errorf("Expected message %q, got %q", tc.message, decodeErr.Error())
+			}
+
+			if decodeErr.Field != tc.field || decodeErr.Expected != tc.expected || decodeErr.Got != tc.got {
+				t.Errorf("Expected %s/%s/%s, got %s/%s/%s", tc.field, tc.expected, tc.got, decodeErr.Field, decodeErr.Expected, decodeErr.Got)
+			}
+
+			if decodeErr.Offset == 0 {
+				t.Errorf("Expected a byte offset")
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package bodyrest
+
+import "encoding/json"
+
+// WithDecoder lets the route configure the json.Decoder used for its request
+// body, e.g. to call DisallowUnknownFields.
+func WithDecoder(configure func(dec *json.Decoder)) Option {
+	return func(cfg *routeConfig) {
+		cfg.decoderOptions = append(cfg.decoderOptions, configure)
+	}
+}
+
+// WithUseNumber decodes numbers into interface{} fields as json.Number
+// instead of float64, so large integers keep their precision.
+func WithUseNumber() Option {
+	return WithDecoder(func(dec *json.Decoder) {
+		dec.UseNumber()
+	})
+}
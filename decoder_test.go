@@ -0,0 +1,71 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDecoderOptions(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		opts         []Option
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "float64 by default",
+			body:         `{"id":9007199254740993}`,
+			expectedCode: http.StatusOK,
+			expectedBody: "float64 9.007199254740992e+15",
+		},
+		{
+			name:         "use number",
+			body:         `{"id":9007199254740993}`,
+			opts:         []Option{WithUseNumber()},
+			expectedCode: http.StatusOK,
+			expectedBody: "json.Number 9007199254740993",
+		},
+		{
+			name: "custom decoder configuration",
+			body: `{"id":1,"extra":true}`,
+			opts: []Option{WithDecoder(func(dec *json.Decoder) {
+				dec.DisallowUnknownFields()
+			})},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/events", strings.NewReader(tc.body))
+
+			r := chi.NewRouter()
+			r.Post("/events", HandleTo(func(e struct {
+				ID any `json:"id"`
+			}) (string, error) {
+				return fmt.Sprintf("%T %v", e.ID, e.ID), nil
+			}, tc.opts...))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			var body string
+			if tc.expectedBody != "" {
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if body != tc.expectedBody {
+					t.Errorf("Expected body %s, got %s", tc.expectedBody, body)
+				}
+			}
+		})
+	}
+}
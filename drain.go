@@ -0,0 +1,112 @@
+package bodyrest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrDraining rejects requests once Drain was called. It wraps
+// ErrServiceUnavailable and so is mapped to 503.
+var ErrDraining = fmt.Errorf("draining: %w", ErrServiceUnavailable)
+
+var drain struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// Drain stops bodyrest-wrapped handlers and JSON-RPC endpoints from
+// accepting requests, answering them with ErrDraining, and waits for the
+// active ones to return. It returns
+// ctx.Err() if they are still running when ctx is done. Call it before
+// http.Server.Shutdown, which then only has idle connections left to close:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	bodyrest.Drain(ctx)
+//	srv.Shutdown(ctx)
+//
+// WebSocket, SSE, long-poll and StreamJSON requests have their context
+// cancelled by Drain, so their handlers should return once it is done.
+func Drain(ctx context.Context) error {
+	drain.mu.Lock()
+	drain.draining = true
+	drainSignal()
+	drain.cancel()
+	if drain.active == 0 {
+		drain.mu.Unlock()
+		return nil
+	}
+	if drain.idle == nil {
+		drain.idle = make(chan struct{})
+	}
+	idle := drain.idle
+	drain.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// admitRequest rejects r during maintenance or once draining started, and
+// otherwise tracks it as active until the returned func is called.
+func admitRequest(w http.ResponseWriter, r *http.Request) (func(), bool) {
+	if rejectMaintenance(w, r) {
+		return nil, false
+	}
+
+	drain.mu.Lock()
+	if drain.draining {
+		drain.mu.Unlock()
+		writeError(w, r, statusFromError(ErrDraining), ErrDraining)
+		return nil, false
+	}
+	drain.active++
+	drain.mu.Unlock()
+
+	return finishRequest, true
+}
+
+// drainSignal returns the context cancelled by Drain. drain.mu must be held.
+func drainSignal() context.Context {
+	if drain.ctx == nil {
+		drain.ctx, drain.cancel = context.WithCancel(context.Background())
+	}
+
+	return drain.ctx
+}
+
+// withDrainSignal returns a copy of ctx that is also done once Drain is
+// called, for long-lived requests that would otherwise hold Drain until its
+// deadline.
+func withDrainSignal(ctx context.Context) (context.Context, context.CancelFunc) {
+	drain.mu.Lock()
+	signal := drainSignal()
+	drain.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(signal, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+func finishRequest() {
+	drain.mu.Lock()
+	defer drain.mu.Unlock()
+
+	drain.active--
+	if drain.active == 0 && drain.idle != nil {
+		close(drain.idle)
+		drain.idle = nil
+	}
+}
@@ -0,0 +1,141 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func resetDrain() {
+	drain.mu.Lock()
+	drain.draining = false
+	drain.ctx = nil
+	drain.mu.Unlock()
+}
+
+func TestDrain(t *testing.T) {
+	defer resetDrain()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	r := chi.NewRouter()
+	r.Get("/slow", HandleTo(func() (int, any, error) {
+		close(started)
+		<-release
+		return http.StatusOK, "done", nil
+	}))
+	r.Get("/fast", HandleTo(func() (int, any, error) {
+		return http.StatusOK, "done", nil
+	}))
+
+	inFlight := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		r.ServeHTTP(inFlight, httptest.NewRequest("GET", "/slow", nil))
+		close(served)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected drain to time out with a handler running, got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d while draining, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	drained := make(chan error)
+	go func() {
+		drained <- Drain(context.Background())
+	}()
+	close(release)
+
+	if err := <-drained; err != nil {
+		t.Errorf("Expected drain to finish, got %v", err)
+	}
+	<-served
+	if inFlight.Code != http.StatusOK {
+		t.Errorf("Expected in-flight request to complete with %d, got %d", http.StatusOK, inFlight.Code)
+	}
+}
+
+func TestDrainCancelsStreams(t *testing.T) {
+	defer resetDrain()
+
+	started := make(chan struct{})
+	r := chi.NewRouter()
+	r.Get("/events", HandleSSE(func(sink EventSink) error {
+		close(started)
+		<-sink.Context().Done()
+		return sink.Context().Err()
+	}))
+
+	served := make(chan struct{})
+	go func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+		close(served)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := Drain(ctx); err != nil {
+		t.Errorf("Expected drain to end the event stream, got %v", err)
+	}
+	<-served
+}
+
+func TestDrainJSONRPC(t *testing.T) {
+	defer resetDrain()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	rpc := NewJSONRPC()
+	rpc.Register("user.import", func(u testUser) (testUser, error) {
+		close(started)
+		<-release
+		return u, nil
+	})
+
+	inFlight := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		rpc.ServeHTTP(inFlight, httptest.NewRequest("POST", "/rpc", strings.NewReader(`{"jsonrpc":"2.0","method":"user.import","params":{"name":"john"},"id":1}`)))
+		close(served)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected drain to time out with a call running, got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	rpc.ServeHTTP(w, httptest.NewRequest("POST", "/rpc", strings.NewReader(`{"jsonrpc":"2.0","method":"user.import","params":{"name":"jane"},"id":2}`)))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d while draining, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	drained := make(chan error)
+	go func() {
+		drained <- Drain(context.Background())
+	}()
+	close(release)
+
+	if err := <-drained; err != nil {
+		t.Errorf("Expected drain to finish, got %v", err)
+	}
+	<-served
+	if inFlight.Code != http.StatusOK {
+		t.Errorf("Expected in-flight call to complete with %d, got %d", http.StatusOK, inFlight.Code)
+	}
+}
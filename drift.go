@@ -0,0 +1,307 @@
+package bodyrest
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Change is a breaking difference between the routes registered on a
+// Router and a committed OpenAPI document, as reported by Drift.
+type Change struct {
+	Method  string
+	Pattern string
+	// Field is the JSON path of the field in the request or response body,
+	// e.g. "request.address.city", or empty for the route itself.
+	Field   string
+	Message string
+}
+
+func (c Change) String() string {
+	if c.Field == "" {
+		return fmt.Sprintf("%s %s: %s", c.Method, c.Pattern, c.Message)
+	}
+
+	return fmt.Sprintf("%s %s: %s: %s", c.Method, c.Pattern, c.Field, c.Message)
+}
+
+// Drift compares the routes registered on rt with spec, an OpenAPI 3
+// document in JSON, and reports the breaking changes: operations of the
+// document that are no longer registered, body fields that were removed or
+// changed type, and top level request fields that became required. It only looks at
+// application/json bodies, and at the first 2xx response of each operation.
+// Run it before a release against the document clients were built from.
+func Drift(rt *Router, spec []byte) ([]Change, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	routes := map[string]RouteInfo{}
+	for _, route := range rt.Routes() {
+		routes[route.Method+" "+openAPIPath(route.Pattern)] = route
+	}
+
+	var changes []Change
+	for _, path := range sortedKeys(doc.Paths) {
+		for _, method := range sortedKeys(doc.Paths[path]) {
+			if !slices.Contains(routingMethods, strings.ToUpper(method)) {
+				continue
+			}
+
+			var op openAPIOperation
+			if err := json.Unmarshal(doc.Paths[path][method], &op); err != nil {
+				return nil, fmt.Errorf("failed to parse operation %s %s: %w", method, path, err)
+			}
+
+			method = strings.ToUpper(method)
+			route, ok := routes[method+" "+path]
+			if !ok {
+				changes = append(changes, Change{Method: method, Pattern: path, Message: "route removed"})
+				continue
+			}
+
+			d := &drift{doc: &doc, route: route}
+			d.compareRequest(op)
+			d.compareResponse(op)
+			changes = append(changes, d.changes...)
+		}
+	}
+
+	return changes, nil
+}
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	RequestBody *openAPIBody           `json:"requestBody"`
+	Responses   map[string]openAPIBody `json:"responses"`
+}
+
+type openAPIBody struct {
+	Content map[string]struct {
+		Schema *openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+func (b *openAPIBody) jsonSchema() *openAPISchema {
+	if b == nil {
+		return nil
+	}
+
+	return b.Content["application/json"].Schema
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       json.RawMessage           `json:"type"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	Required   []string                  `json:"required"`
+}
+
+// typeName returns the type of s, leaving out "null" from OpenAPI 3.1 type
+// lists.
+func (s *openAPISchema) typeName() string {
+	var name string
+	if json.Unmarshal(s.Type, &name) == nil {
+		return name
+	}
+
+	var names []string
+	json.Unmarshal(s.Type, &names)
+	for _, name := range names {
+		if name != "null" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// drift collects the changes of one route.
+type drift struct {
+	doc     *openAPIDocument
+	route   RouteInfo
+	changes []Change
+}
+
+func (d *drift) add(field, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Method:  d.route.Method,
+		Pattern: openAPIPath(d.route.Pattern),
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *drift) compareRequest(op openAPIOperation) {
+	schema := op.RequestBody.jsonSchema()
+	bodyType, ok := requestBodyType(d.route.HandlerType)
+	if schema == nil {
+		if ok {
+			d.add("request", "request body added")
+		}
+		return
+	}
+
+	if !ok {
+		d.add("request", "request body removed")
+		return
+	}
+
+	d.compare("request", schema, bodyType, true, 0)
+}
+
+func (d *drift) compareResponse(op openAPIOperation) {
+	if d.route.HandlerType == nil {
+		return
+	}
+
+	var schema *openAPISchema
+	for _, status := range sortedKeys(op.Responses) {
+		if strings.HasPrefix(status, "2") {
+			body := op.Responses[status]
+			schema = body.jsonSchema()
+			break
+		}
+	}
+
+	responseType := declaredResponseType(d.route.HandlerType, resultFormOf(d.route.HandlerType), newRouteConfig(nil))
+	if schema == nil || responseType == nil {
+		return
+	}
+
+	d.compare("response", schema, responseType, false, 0)
+}
+
+// maxDriftDepth bounds the comparison of recursive schemas.
+const maxDriftDepth = 32
+
+func (d *drift) compare(path string, schema *openAPISchema, t reflect.Type, request bool, depth int) {
+	schema = d.resolve(schema, 0)
+	if schema == nil || depth > maxDriftDepth {
+		return
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	specType, goType := schema.typeName(), schemaTypeOf(t)
+	if specType != "" && goType != "" && specType != goType {
+		d.add(path, "type changed from %s to %s", specType, goType)
+		return
+	}
+
+	switch {
+	case goType == "array" && schema.Items != nil:
+		d.compare(path+"[]", schema.Items, t.Elem(), request, depth+1)
+	case goType == "object" && t.Kind() == reflect.Struct:
+		fields := map[string]reflect.StructField{}
+		for _, field := range bodyFields(t) {
+			fields[jsonFieldName(field)] = field
+		}
+
+		for _, name := range sortedKeys(schema.Properties) {
+			field, ok := fields[name]
+			if !ok {
+				d.add(path+"."+name, "field removed")
+				continue
+			}
+
+			d.compare(path+"."+name, schema.Properties[name], field.Type, request, depth+1)
+		}
+
+		// only the top level fields of the body are validated as required
+		if !request || depth > 0 {
+			return
+		}
+		for _, name := range sortedKeys(fields) {
+			if isFieldRequired(fields[name]) && !slices.Contains(schema.Required, name) {
+				d.add(path+"."+name, "field is now required")
+			}
+		}
+	}
+}
+
+// resolve follows the local $ref of schema to a component schema.
+func (d *drift) resolve(schema *openAPISchema, depth int) *openAPISchema {
+	if schema == nil || schema.Ref == "" || depth > maxDriftDepth {
+		return schema
+	}
+
+	name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+	if !ok {
+		return nil
+	}
+
+	return d.resolve(d.doc.Components.Schemas[name], depth+1)
+}
+
+var textMarshalerType = typeOf[encoding.TextMarshaler]()
+
+// schemaTypeOf returns the JSON schema type values of t are encoded as, or
+// "" when it cannot tell.
+func schemaTypeOf(t reflect.Type) string {
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return ""
+}
+
+// openAPIPath returns a chi pattern as an OpenAPI path, without the regexp
+// constraints of its placeholders.
+func openAPIPath(pattern string) string {
+	var b strings.Builder
+	last := 0
+	for _, p := range parsePlaceholders(pattern) {
+		b.WriteString(pattern[last:p.start])
+		b.WriteString("{" + p.name + "}")
+		last = p.end
+	}
+	b.WriteString(pattern[last:])
+
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
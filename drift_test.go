@@ -0,0 +1,82 @@
+package bodyrest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDriftAddress struct {
+	City string `json:"city"`
+}
+
+type testDriftUser struct {
+	Name    string           `json:"name"`
+	Age     string           `json:"age,omitempty"`
+	Email   string           `json:"email"`
+	Address testDriftAddress `json:"address"`
+	Created time.Time        `json:"created,omitempty"`
+}
+
+const testDriftSpec = `{
+  "openapi": "3.1.0",
+  "paths": {
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path"}],
+      "put": {
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+        "responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}
+      },
+      "delete": {"responses": {"204": {}}}
+    }
+  },
+  "components": {
+    "schemas": {
+      "User": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "age": {"type": ["integer", "null"]},
+          "nickname": {"type": "string"},
+          "created": {"type": "string"},
+          "address": {"type": "object", "properties": {"city": {"type": "string"}, "zip": {"type": "string"}}}
+        }
+      }
+    }
+  }
+}`
+
+func TestDrift(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Put("/users/{id:[0-9]+}", func(id int, u testDriftUser) (testDriftUser, error) { return u, nil })
+
+	changes, err := Drift(rt, []byte(testDriftSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+
+	expected := []string{
+		"DELETE /users/{id}: route removed",
+		"PUT /users/{id}: request.address.zip: field removed",
+		"PUT /users/{id}: request.age: type changed from integer to string",
+		"PUT /users/{id}: request.nickname: field removed",
+		"PUT /users/{id}: request.address: field is now required",
+		"PUT /users/{id}: request.email: field is now required",
+		"PUT /users/{id}: response.address.zip: field removed",
+		"PUT /users/{id}: response.age: type changed from integer to string",
+		"PUT /users/{id}: response.nickname: field removed",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if _, err := Drift(rt, []byte("openapi: 3.1.0")); err == nil {
+		t.Error("Expected an error for a document that is not JSON")
+	}
+}
@@ -0,0 +1,26 @@
+package bodyrest
+
+import "fmt"
+
+// WithRejectDuplicateKeys rejects request bodies that repeat a key within
+// the same JSON object with 400. encoding/json silently keeps the last value,
+// which lets different parsers in a chain see different payloads.
+func WithRejectDuplicateKeys() Option {
+	return func(cfg *routeConfig) {
+		cfg.rejectDuplicateKeys = true
+	}
+}
+
+// DuplicateKeyError reports a key repeated within one JSON object.
+type DuplicateKeyError struct {
+	Key  string
+	Path string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("duplicate key %q", e.Key)
+	}
+
+	return fmt.Sprintf("duplicate key %q in %s", e.Key, e.Path)
+}
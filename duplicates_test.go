@@ -0,0 +1,58 @@
+package bodyrest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCheckDuplicateKeys(t *testing.T) {
+	testCases := []struct {
+		name        string
+		jsonPayload string
+		expectedErr string
+	}{
+		{name: "No duplicates", jsonPayload: `{"a":1,"b":{"a":2},"c":[{"a":1},{"a":2}]}`},
+		{name: "Top level duplicate", jsonPayload: `{"role":"user","role":"admin"}`, expectedErr: `duplicate key "role"`},
+		{name: "Nested duplicate", jsonPayload: `{"a":{"b":1,"b":2}}`, expectedErr: `duplicate key "b" in a`},
+		{name: "Duplicate in array item", jsonPayload: `{"c":[{"a":1},{"a":1,"a":2}]}`, expectedErr: `duplicate key "a" in c[1]`},
+		{name: "String values are not keys", jsonPayload: `{"a":"a","b":"a"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := scanJSON([]byte(tc.jsonPayload), true, JSONLimits{})
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var dupErr *DuplicateKeyError
+			if !errors.As(err, &dupErr) || err.Error() != tc.expectedErr {
+				t.Errorf("Expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestWithRejectDuplicateKeys(t *testing.T) {
+	req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"john","name":"admin"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Post("/users", HandleTo(testImportUser, WithRejectDuplicateKeys()))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
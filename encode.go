@@ -0,0 +1,333 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	responseIndent string
+	escapeHTML     = true
+	timeFormat     string
+)
+
+// SetResponseIndent makes auto-encoded responses indented with indent, e.g.
+// "  " for debugging. Streams are never indented so NDJSON stays one item
+// per line. An empty indent restores compact output.
+func SetResponseIndent(indent string) {
+	responseIndent = indent
+}
+
+// SetEscapeHTML sets whether <, > and & are escaped in encoded responses. It
+// is enabled by default, like in encoding/json.
+func SetEscapeHTML(escape bool) {
+	escapeHTML = escape
+}
+
+// SetTimeFormat sets the layout, e.g. time.DateTime, used for time.Time
+// values in encoded responses instead of RFC 3339. An empty layout restores
+// the default.
+func SetTimeFormat(layout string) {
+	timeFormat = layout
+}
+
+// marshalJSON encodes v for a response with the configured serialization
+// options, without a trailing newline.
+func marshalJSON(v any, indent bool) ([]byte, error) {
+	return marshalShaped(v, indent, nil)
+}
+
+// marshalShaped is marshalJSON leaving out the struct fields shape excludes.
+func marshalShaped(v any, indent bool, shape *responseShape) ([]byte, error) {
+	if timeFormat != "" || shape.appliesTo(reflect.TypeOf(v)) {
+		v = shapeValue(reflect.ValueOf(v), shape)
+	}
+
+	return encodeJSON(v, indent)
+}
+
+func encodeJSON(v any, indent bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(escapeHTML)
+	if indent && responseIndent != "" {
+		enc.SetIndent("", responseIndent)
+	}
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// marshalerOf returns v, or its address when only the pointer implements
+// them, if it encodes itself through json.Marshaler or
+// encoding.TextMarshaler, to be left to encoding/json.
+func marshalerOf(v reflect.Value) (any, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return v.Interface(), true
+	}
+
+	if v.CanAddr() && (reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)) {
+		return v.Addr().Interface(), true
+	}
+
+	return nil, false
+}
+
+// shapeValue returns a copy of v that encodes like v, except that time.Time
+// values are strings in timeFormat, if set, and struct fields excluded by
+// shape are left out. Struct field order is preserved.
+func shapeValue(v reflect.Value, shape *responseShape) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if timeFormat != "" && v.Type() == timeType {
+		return v.Interface().(time.Time).Format(timeFormat)
+	}
+
+	if marshaler, ok := marshalerOf(v); ok {
+		return marshaler
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return shapeValue(v.Elem(), shape)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if timeFormat == "" && !shape.appliesTo(v.Elem().Type()) {
+			return v.Interface()
+		}
+		return shapeValue(v.Elem(), shape)
+	case reflect.Struct:
+		var obj orderedObject
+		shapeStruct(v, &obj, shape)
+		if shape != nil {
+			if links := linksOf(v); len(links) > 0 {
+				obj = append(obj, objectMember{name: "_links", value: links})
+			}
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = shapeValue(v.Index(i), shape)
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, ok := mapKeyName(iter.Key())
+			if !ok {
+				// left to encoding/json to report
+				return v.Interface()
+			}
+			out[key] = shapeValue(iter.Value(), shape)
+		}
+		return out
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+}
+
+// mapKeyName returns the JSON object key encoding/json writes for the map
+// key k: strings as is, text marshalers as their text and integers in
+// decimal.
+func mapKeyName(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.String {
+		return k.String(), true
+	}
+
+	if marshaler, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", true
+		}
+		text, err := marshaler.MarshalText()
+		return string(text), err == nil
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+
+	return "", false
+}
+
+func shapeStruct(v reflect.Value, obj *orderedObject, shape *responseShape) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if isJSONEmbedded(field) {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			shapeStruct(embedded, obj, shape)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if hasTagOption(tag, "omitempty") && isEmptyJSONValue(v.Field(i)) || !shape.includes(field) {
+			continue
+		}
+
+		var value any
+		if hasTagOption(tag, "string") {
+			value = quotedValue(v.Field(i), shape)
+		} else {
+			value = shapeValue(v.Field(i), shape)
+		}
+		*obj = append(*obj, objectMember{name: jsonFieldName(field), value: value})
+	}
+}
+
+// isJSONEmbedded reports whether encoding/json promotes the fields of field:
+// an embedded struct, or pointer to one, exported or not, with no JSON name.
+func isJSONEmbedded(field reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return false
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func hasTagOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	return slices.Contains(strings.Split(opts, ","), option)
+}
+
+// quotedValue returns the value of a field tagged with the ",string" option,
+// which encoding/json encodes inside a JSON string for strings, numbers and
+// booleans that do not marshal themselves, and as usual otherwise.
+func quotedValue(v reflect.Value, shape *responseShape) any {
+	if marshaler, ok := marshalerOf(v); ok {
+		return marshaler
+	}
+
+	scalar := v
+	if scalar.Kind() == reflect.Ptr {
+		if scalar.IsNil() {
+			return nil
+		}
+		scalar = scalar.Elem()
+	}
+
+	switch scalar.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		encoded, err := json.Marshal(scalar.Interface())
+		if err != nil {
+			return scalar.Interface()
+		}
+		return string(encoded)
+	}
+
+	return shapeValue(v, shape)
+}
+
+// isEmptyJSONValue reports whether encoding/json omits v from a field tagged
+// omitempty.
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+
+	return false
+}
+
+type objectMember struct {
+	name  string
+	value any
+}
+
+// orderedObject is a JSON object keeping the order of its members.
+type orderedObject []objectMember
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := encodeJSON(member.name, false)
+		if err != nil {
+			return nil, err
+		}
+		value, err := encodeJSON(member.value, false)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
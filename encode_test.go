@@ -0,0 +1,196 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testEvent struct {
+	Title   string     `json:"title"`
+	At      time.Time  `json:"at"`
+	EndsAt  *time.Time `json:"endsAt,omitempty"`
+	Tags    []string   `json:"tags,omitempty"`
+	private string
+}
+
+func TestResponseSerializationOptions(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	event := testEvent{Title: "<launch> & party", At: at, private: "x"}
+
+	testCases := []struct {
+		name         string
+		configure    func()
+		expectedBody string
+	}{
+		{
+			name:         "defaults",
+			configure:    func() {},
+			expectedBody: `{"title":"\u003claunch\u003e \u0026 party","at":"2024-05-01T09:30:00Z"}` + "\n",
+		},
+		{
+			name: "time format and no HTML escaping",
+			configure: func() {
+				SetTimeFormat(time.DateTime)
+				SetEscapeHTML(false)
+			},
+			expectedBody: `{"title":"<launch> & party","at":"2024-05-01 09:30:00"}` + "\n",
+		},
+		{
+			name: "indent",
+			configure: func() {
+				SetResponseIndent("  ")
+			},
+			expectedBody: "{\n  \"title\": \"\\u003claunch\\u003e \\u0026 party\",\n  \"at\": \"2024-05-01T09:30:00Z\"\n}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.configure()
+			defer func() {
+				SetTimeFormat("")
+				SetEscapeHTML(true)
+				SetResponseIndent("")
+			}()
+
+			r := chi.NewRouter()
+			r.Get("/events/1", HandleTo(func() (testEvent, error) {
+				return event, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/events/1", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type testCode string
+
+func (c *testCode) MarshalText() ([]byte, error) {
+	return []byte("code-" + string(*c)), nil
+}
+
+type testDevice struct {
+	Addr    netip.Addr `json:"addr"`
+	Code    testCode   `json:"code"`
+	Count   int64      `json:"count,string"`
+	Limit   *int       `json:"limit,string"`
+	Enabled bool       `json:"enabled,string"`
+	SeenAt  time.Time  `json:"seenAt"`
+}
+
+func TestTimeFormatLeavesMarshalers(t *testing.T) {
+	SetTimeFormat(time.DateTime)
+	defer SetTimeFormat("")
+
+	limit := 5
+	device := &testDevice{
+		Addr:    netip.MustParseAddr("10.0.0.1"),
+		Code:    "a1",
+		Count:   42,
+		Limit:   &limit,
+		Enabled: true,
+		SeenAt:  time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/devices/1", HandleTo(func() (*testDevice, error) {
+		return device, nil
+	}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/devices/1", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	expected, _ := json.Marshal(device)
+	expectedBody := strings.Replace(string(expected), "2024-05-01T09:30:00Z", "2024-05-01 09:30:00", 1) + "\n"
+	if w.Body.String() != expectedBody {
+		t.Errorf("Expected body %s, got %s", expectedBody, w.Body.String())
+	}
+}
+
+type testEmbeddedBase struct {
+	ID int `json:"id"`
+}
+
+type TestEmbeddedNamed struct {
+	Name string `json:"name"`
+}
+
+type TestEmbeddedTagged struct {
+	Note string `json:"note"`
+}
+
+func TestShapeValueMatchesEncodingJSON(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name  string
+		value any
+	}{
+		{
+			name: "unexported and pointer embedded structs",
+			value: struct {
+				testEmbeddedBase
+				*TestEmbeddedNamed
+				At time.Time `json:"at"`
+			}{testEmbeddedBase{ID: 1}, &TestEmbeddedNamed{Name: "n"}, at},
+		},
+		{
+			name: "nil embedded pointer",
+			value: struct {
+				*TestEmbeddedNamed
+				At time.Time `json:"at"`
+			}{At: at},
+		},
+		{
+			name: "named embedded struct",
+			value: struct {
+				TestEmbeddedTagged `json:"tagged"`
+				TestEmbeddedNamed
+			}{TestEmbeddedTagged{Note: "x"}, TestEmbeddedNamed{Name: "n"}},
+		},
+		{
+			name: "ignored embedded struct",
+			value: struct {
+				TestEmbeddedTagged `json:"-"`
+				ID                 int `json:"id"`
+			}{TestEmbeddedTagged{Note: "x"}, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			shaped, err := encodeJSON(shapeValue(reflect.ValueOf(tc.value), &responseShape{}), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(shaped) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, shaped)
+			}
+		})
+	}
+}
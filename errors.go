@@ -0,0 +1,111 @@
+package bodyrest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+type errorMapping struct {
+	match  func(err error) bool
+	status int
+}
+
+var (
+	errorRegistryMu sync.RWMutex
+	errorRegistry   []errorMapping
+)
+
+type errorContextKey struct{}
+
+type errorReferenceContextKey struct{}
+
+// RegisterError maps errors matching target (via errors.Is) to the given
+// HTTP status when they are returned by a handler.
+func RegisterError(target error, status int) {
+	registerErrorMapping(func(err error) bool {
+		return errors.Is(err, target)
+	}, status)
+}
+
+// RegisterErrorType maps errors of type T (via errors.As) to the given
+// HTTP status when they are returned by a handler.
+func RegisterErrorType[T error](status int) {
+	registerErrorMapping(func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}, status)
+}
+
+func registerErrorMapping(match func(err error) bool, status int) {
+	errorRegistryMu.Lock()
+	defer errorRegistryMu.Unlock()
+
+	errorRegistry = append(errorRegistry, errorMapping{match: match, status: status})
+}
+
+func statusFromError(err error) int {
+	errorRegistryMu.RLock()
+	defer errorRegistryMu.RUnlock()
+
+	for _, m := range errorRegistry {
+		if m.match(err) {
+			return m.status
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ErrorFromRequest returns the error that caused the rest error handler to
+// be called, if any.
+func ErrorFromRequest(r *http.Request) error {
+	err, _ := r.Context().Value(errorContextKey{}).(error)
+	return err
+}
+
+// ErrorReference returns the short reference generated for a 5xx error, so
+// the error handler can show it to the client. The same reference is logged
+// with the error.
+func ErrorReference(r *http.Request) string {
+	ref, _ := r.Context().Value(errorReferenceContextKey{}).(string)
+	return ref
+}
+
+func newErrorReference() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	w.Header().Set("Cache-Control", "no-store")
+	setRetryAfter(w, err)
+
+	if status >= http.StatusInternalServerError {
+		ref := newErrorReference()
+		log.Printf("error reference %s: status %d: %v\n", ref, status, err)
+		r = r.WithContext(context.WithValue(r.Context(), errorReferenceContextKey{}, ref))
+		reportError(r, status, err)
+	}
+
+	if restErrorFunc != nil {
+		if err != nil {
+			r = r.WithContext(context.WithValue(r.Context(), errorContextKey{}, err))
+		}
+		restErrorFunc(w, r, status)
+		return
+	}
+
+	http.Error(w, defaultResponse, status)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
@@ -0,0 +1,68 @@
+package bodyrest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testStatusError struct{}
+
+func (testStatusError) Error() string { return "typed" }
+
+func TestStatusFromError(t *testing.T) {
+	errNotFound := errors.New("not found")
+	RegisterError(errNotFound, http.StatusNotFound)
+	RegisterErrorType[testStatusError](http.StatusUnprocessableEntity)
+
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "Sentinel error", err: errNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Wrapped sentinel error", err: fmt.Errorf("lookup: %w", errNotFound), expectedStatus: http.StatusNotFound},
+		{name: "Typed error", err: fmt.Errorf("wrap: %w", testStatusError{}), expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Unknown error", err: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if status := statusFromError(tc.err); status != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestErrorReference(t *testing.T) {
+	testCases := []struct {
+		name              string
+		status            int
+		expectedReference bool
+	}{
+		{name: "Server error has reference", status: http.StatusInternalServerError, expectedReference: true},
+		{name: "Client error has no reference", status: http.StatusBadRequest, expectedReference: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ref string
+			saved := restErrorFunc
+			restErrorFunc = func(w http.ResponseWriter, r *http.Request, status int) {
+				ref = ErrorReference(r)
+				w.WriteHeader(status)
+			}
+			defer func() { restErrorFunc = saved }()
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			writeError(httptest.NewRecorder(), req, tc.status, errors.New("boom"))
+
+			if (ref != "") != tc.expectedReference {
+				t.Errorf("Expected reference %v, got %q", tc.expectedReference, ref)
+			}
+		})
+	}
+}
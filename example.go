@@ -0,0 +1,15 @@
+package bodyrest
+
+// Example is a sample request and response payload of a route.
+type Example struct {
+	Request  any
+	Response any
+}
+
+// WithExample attaches a sample request and response to the route. Examples
+// are listed in the RouteInfo of routes registered through a Router.
+func WithExample(request, response any) Option {
+	return func(cfg *routeConfig) {
+		cfg.examples = append(cfg.examples, Example{Request: request, Response: response})
+	}
+}
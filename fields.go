@@ -0,0 +1,115 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const fieldsQueryParam = "fields"
+
+// WithFieldMask lets clients prune the auto-encoded response with the fields
+// query parameter, e.g. ?fields=id,name,address.city. Only the listed paths
+// and their children may be requested.
+func WithFieldMask(allowed ...string) Option {
+	return func(cfg *routeConfig) {
+		cfg.maskableFields = append(cfg.maskableFields, allowed...)
+	}
+}
+
+// requestedFields returns the field paths requested by the client, or an
+// error if the route does not allow one of them.
+func requestedFields(r *http.Request, cfg *routeConfig) ([]string, error) {
+	if len(cfg.maskableFields) == 0 {
+		return nil, nil
+	}
+
+	raw := r.URL.Query().Get(fieldsQueryParam)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if !isFieldAllowed(field, cfg.maskableFields) {
+			return nil, fmt.Errorf("field %q cannot be selected", field)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func isFieldAllowed(field string, allowed []string) bool {
+	for _, a := range allowed {
+		if field == a || strings.HasPrefix(field, a+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskPayload keeps only the given dotted paths of an encoded JSON payload.
+// Arrays are masked element by element.
+func maskPayload(payload []byte, fields []string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	paths := make([][]string, len(fields))
+	for i, field := range fields {
+		paths[i] = strings.Split(field, ".")
+	}
+
+	return encodeJSON(maskValue(value, paths), true)
+}
+
+func maskValue(value any, paths [][]string) any {
+	switch v := value.(type) {
+	case []any:
+		for i := range v {
+			v[i] = maskValue(v[i], paths)
+		}
+		return v
+	case map[string]any:
+		children := map[string][][]string{}
+		for _, path := range paths {
+			if _, ok := children[path[0]]; ok && children[path[0]] == nil {
+				continue
+			}
+			if len(path) == 1 {
+				children[path[0]] = nil
+				continue
+			}
+			children[path[0]] = append(children[path[0]], path[1:])
+		}
+
+		masked := make(map[string]any, len(children))
+		for key, rest := range children {
+			child, ok := v[key]
+			if !ok {
+				continue
+			}
+			if rest == nil {
+				masked[key] = child
+				continue
+			}
+			masked[key] = maskValue(child, rest)
+		}
+		return masked
+	default:
+		return value
+	}
+}
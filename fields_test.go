@@ -0,0 +1,83 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testProfile struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Address struct {
+		City   string `json:"city"`
+		Street string `json:"street"`
+	} `json:"address"`
+}
+
+func testGetProfile(id int) (int, any, error) {
+	profile := testProfile{ID: id, Name: "john", Email: "john@example.com"}
+	profile.Address.City = "Oslo"
+	profile.Address.Street = "Main"
+	return http.StatusOK, []testProfile{profile}, nil
+}
+
+func TestFieldMask(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "No selection",
+			path:           "/profiles/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"name":"john","email":"john@example.com","address":{"city":"Oslo","street":"Main"}}]`,
+		},
+		{
+			name:           "Top level and nested fields",
+			path:           "/profiles/1?fields=id,address.city",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"address":{"city":"Oslo"},"id":1}]`,
+		},
+		{
+			name:           "Whole object wins over nested field",
+			path:           "/profiles/1?fields=address.city,address",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"address":{"city":"Oslo","street":"Main"}}]`,
+		},
+		{
+			name:           "Field outside whitelist",
+			path:           "/profiles/1?fields=email",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/profiles/{id}", HandleTo(testGetProfile, WithFieldMask("id", "name", "address")))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
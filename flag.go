@@ -0,0 +1,61 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrFeatureDisabled rejects requests to a route whose feature flag is off.
+// It is mapped to 404 unless the route sets another status with
+// WithDisabledStatus.
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+func init() {
+	RegisterError(ErrFeatureDisabled, http.StatusNotFound)
+}
+
+// FlagResolver reports whether flag is enabled for r, e.g. by environment or
+// by the tenant of the request.
+type FlagResolver func(r *http.Request, flag string) bool
+
+// WithFeatureFlag rejects requests with ErrFeatureDisabled while resolver
+// reports flag as disabled, before any binding or handler work is done.
+// Routes with several flags need all of them enabled.
+func WithFeatureFlag(flag string, resolver FlagResolver) Option {
+	return func(cfg *routeConfig) {
+		cfg.featureFlags = append(cfg.featureFlags, routeFlag{name: flag, resolver: resolver})
+	}
+}
+
+// WithDisabledStatus sets the status written when a feature flag of the
+// route is disabled, e.g. 503 for features being rolled out rather than
+// hidden.
+func WithDisabledStatus(status int) Option {
+	return func(cfg *routeConfig) {
+		cfg.disabledStatus = status
+	}
+}
+
+type routeFlag struct {
+	name     string
+	resolver FlagResolver
+}
+
+// featureEnabled reports whether every feature flag of the route is enabled
+// for r, writing the error response otherwise.
+func featureEnabled(w http.ResponseWriter, r *http.Request, cfg *routeConfig) bool {
+	for _, flag := range cfg.featureFlags {
+		if flag.resolver(r, flag.name) {
+			continue
+		}
+
+		status := cfg.disabledStatus
+		if status == 0 {
+			status = statusFromError(ErrFeatureDisabled)
+		}
+		writeError(w, r, status, ErrFeatureDisabled)
+		return false
+	}
+
+	return true
+}
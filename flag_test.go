@@ -0,0 +1,51 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestWithFeatureFlag(t *testing.T) {
+	resolver := func(r *http.Request, flag string) bool {
+		return flag == "new-billing" && r.Header.Get("X-Tenant") == "beta"
+	}
+	handler := func(u testUser) (testUser, error) {
+		return u, nil
+	}
+
+	r := chi.NewRouter()
+	r.Post("/billing", HandleTo(handler, WithFeatureFlag("new-billing", resolver)))
+	r.Post("/rollout", HandleTo(handler, WithFeatureFlag("new-billing", resolver), WithDisabledStatus(http.StatusServiceUnavailable)))
+	r.Post("/both", HandleTo(handler, WithFeatureFlag("new-billing", resolver), WithFeatureFlag("invoices", resolver)))
+
+	testCases := []struct {
+		name           string
+		path           string
+		tenant         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "enabled", path: "/billing", tenant: "beta", body: `{"name":"john"}`, expectedStatus: http.StatusOK},
+		{name: "disabled", path: "/billing", body: `{"name":"john"}`, expectedStatus: http.StatusNotFound},
+		{name: "disabled before binding", path: "/billing", body: `{"name":`, expectedStatus: http.StatusNotFound},
+		{name: "disabled status", path: "/rollout", body: `{"name":"john"}`, expectedStatus: http.StatusServiceUnavailable},
+		{name: "one of several flags disabled", path: "/both", tenant: "beta", body: `{"name":"john"}`, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("X-Tenant", tc.tenant)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package bodyrest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Function returns the routes of rt as a single function entrypoint, e.g.
+// for Cloud Functions:
+//
+//	func init() {
+//		functions.HTTP("Users", bodyrest.Function(rt, "/users-fn"))
+//	}
+//
+// The platform's path prefix, if any, is stripped before the routes are
+// matched; requests outside it are answered with 404 through the rest error
+// handler.
+func Function(rt *Router, prefix string) func(w http.ResponseWriter, r *http.Request) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if prefix == "" {
+			rt.ServeHTTP(w, r)
+			return
+		}
+
+		path, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok || path != "" && !strings.HasPrefix(path, "/") {
+			writeError(w, r, http.StatusNotFound, nil)
+			return
+		}
+		if path == "" {
+			path = "/"
+		}
+
+		r = r.Clone(r.Context())
+		r.URL.Path = path
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+		}
+
+		rt.ServeHTTP(w, r)
+	}
+}
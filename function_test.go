@@ -0,0 +1,42 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFunction(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Post("/users", testCreateUser)
+	rt.Get("/orgs/{org}/users/{id}", testGetOrgUser)
+
+	testCases := []struct {
+		name           string
+		prefix         string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "no prefix", method: "POST", path: "/users", body: `{"name":"john"}`, expectedStatus: http.StatusCreated},
+		{name: "prefix stripped", prefix: "/users-fn/", method: "POST", path: "/users-fn/users", body: `{"name":"john"}`, expectedStatus: http.StatusCreated},
+		{name: "escaped path", prefix: "/users-fn", method: "GET", path: "/users-fn/orgs/acme%2Fcorp/users/1", expectedStatus: http.StatusOK},
+		{name: "outside prefix", prefix: "/users-fn", method: "POST", path: "/users", body: `{"name":"john"}`, expectedStatus: http.StatusNotFound},
+		{name: "longer prefix", prefix: "/users-fn", method: "POST", path: "/users-fnx/users", body: `{"name":"john"}`, expectedStatus: http.StatusNotFound},
+		{name: "unknown route", prefix: "/users-fn", method: "GET", path: "/users-fn/missing", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			Function(rt, tc.prefix)(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
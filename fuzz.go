@@ -0,0 +1,62 @@
+package bodyrest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// FuzzBind runs data through the same decoding, validation and after-bind
+// steps as a request body bound by HandleTo, with the default route
+// options. target must be a pointer to a struct. It is meant for fuzz
+// tests of request types:
+//
+//	func FuzzCreateUser(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			bodyrest.FuzzBind(data, &CreateUser{})
+//		})
+//	}
+func FuzzBind(data []byte, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("fuzz target must be a non-nil pointer to a struct")
+	}
+
+	r, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	cfg := newRouteConfig(nil)
+	if err := decodeBody(discardResponseWriter{}, r, cfg, target); err != nil {
+		return err
+	}
+
+	if err := validateRequiredFields(target); err != nil {
+		return err
+	}
+
+	if err := bindSources(r, v.Elem()); err != nil {
+		return err
+	}
+
+	if binder, ok := target.(afterBinder); ok {
+		if err := binder.afterBind(); err != nil {
+			return err
+		}
+	}
+
+	if binder, ok := target.(AfterBinder); ok {
+		return binder.AfterBind(r)
+	}
+
+	return nil
+}
+
+// discardResponseWriter drops everything written to it.
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header         { return http.Header{} }
+func (discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
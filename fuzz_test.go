@@ -0,0 +1,94 @@
+package bodyrest
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testFuzzRequest struct {
+	Name    string             `json:"name"`
+	Age     int                `json:"age,omitempty"`
+	Tags    []string           `json:"tags,omitempty"`
+	Meta    map[string]any     `json:"meta,omitempty"`
+	Nick    Nullable[string]   `json:"nick"`
+	Address *struct{ Zip int } `json:"address,omitempty"`
+	PageRequest
+}
+
+func FuzzBindBody(f *testing.F) {
+	f.Add([]byte(`{"name":"John","age":30,"tags":["a"],"meta":{"k":1}}`))
+	f.Add([]byte(`{"name":"","nick":null,"address":{"Zip":"x"}}`))
+	f.Add([]byte(`[1,2,3]`))
+	f.Add([]byte(`{"name":"\u0000","age":1e400}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FuzzBind(data, &testFuzzRequest{})
+	})
+}
+
+func FuzzPathParam(f *testing.F) {
+	f.Add("42")
+	f.Add("-1")
+	f.Add("a%2Fb")
+	f.Add("%zz")
+	f.Add("1e10")
+	f.Add("true")
+
+	kinds := []reflect.Type{
+		reflect.TypeOf(""),
+		reflect.TypeOf(0),
+		reflect.TypeOf(int8(0)),
+		reflect.TypeOf(uint16(0)),
+		reflect.TypeOf(0.0),
+		reflect.TypeOf(false),
+		reflect.TypeOf((*int)(nil)),
+	}
+
+	escaped := httptest.NewRequest("GET", "/files/a%2Fb", nil)
+	f.Fuzz(func(t *testing.T, value string) {
+		decoded, err := decodePathParam(escaped, value)
+		if err != nil {
+			return
+		}
+
+		for _, kind := range kinds {
+			setFieldFromString(reflect.New(kind).Elem(), decoded)
+		}
+	})
+}
+
+func TestFuzzBind(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		target  any
+		isValid bool
+	}{
+		{
+			name:    "valid",
+			data:    `{"name":"John"}`,
+			target:  &testFuzzRequest{},
+			isValid: true,
+		},
+		{
+			name:   "missing required field",
+			data:   `{"age":1}`,
+			target: &testFuzzRequest{},
+		},
+		{
+			name:   "not a pointer",
+			data:   `{"name":"John"}`,
+			target: testFuzzRequest{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := FuzzBind([]byte(tc.data), tc.target)
+			if (err == nil) != tc.isValid {
+				t.Errorf("Expected valid %v, got %v", tc.isValid, err)
+			}
+		})
+	}
+}
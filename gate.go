@@ -0,0 +1,81 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ErrTooManyRequests is returned by gates to reject a request with 429.
+var ErrTooManyRequests = errors.New("too many requests")
+
+func init() {
+	RegisterError(ErrTooManyRequests, http.StatusTooManyRequests)
+}
+
+// GateFunc decides whether a request may reach the handler. A non-nil error
+// is mapped through the error registry and written by the rest error handler.
+type GateFunc func(ctx context.Context, key string) error
+
+// KeyFunc builds the gate key from the bound handler arguments.
+type KeyFunc func(r *http.Request, args []any) string
+
+// WithGate runs gate after the handler arguments are bound and before the
+// handler is called, e.g. to rate limit by a user ID path param. The key is
+// built by key, or from the route pattern and the bound scalar arguments
+// when key is nil.
+func WithGate(gate GateFunc, key KeyFunc) Option {
+	return func(cfg *routeConfig) {
+		if key == nil {
+			key = defaultGateKey
+		}
+		cfg.gates = append(cfg.gates, routeGate{gate: gate, key: key})
+	}
+}
+
+type routeGate struct {
+	gate GateFunc
+	key  KeyFunc
+}
+
+func defaultGateKey(r *http.Request, args []any) string {
+	parts := []string{r.Method}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		parts = append(parts, rctx.RoutePattern())
+	}
+
+	for _, arg := range args {
+		switch reflect.ValueOf(arg).Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface, reflect.Func:
+			continue
+		}
+		parts = append(parts, fmt.Sprint(arg))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// runGates runs the route gates with the bound arguments.
+func runGates(r *http.Request, cfg *routeConfig, args []reflect.Value) error {
+	if len(cfg.gates) == 0 {
+		return nil
+	}
+
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Interface()
+	}
+
+	for _, g := range cfg.gates {
+		if err := g.gate(r.Context(), g.key(r, values)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
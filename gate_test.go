@@ -0,0 +1,61 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testGetUserStatus(id int) (int, any, error) {
+	return http.StatusOK, nil, nil
+}
+
+func TestWithGate(t *testing.T) {
+	var keys []string
+	seen := map[string]int{}
+	gate := func(ctx context.Context, key string) error {
+		keys = append(keys, key)
+		seen[key]++
+		if seen[key] > 1 {
+			return ErrTooManyRequests
+		}
+		return nil
+	}
+
+	r := chi.NewRouter()
+	r.Get("/users/{id}", HandleTo(testGetUserStatus, WithGate(gate, nil)))
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedKey    string
+	}{
+		{name: "First request passes", path: "/users/1", expectedStatus: http.StatusOK, expectedKey: "GET /users/{id} 1"},
+		{name: "Other user passes", path: "/users/2", expectedStatus: http.StatusOK, expectedKey: "GET /users/{id} 2"},
+		{name: "Repeated request is limited", path: "/users/1", expectedStatus: http.StatusTooManyRequests, expectedKey: "GET /users/{id} 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if keys[len(keys)-1] != tc.expectedKey {
+				t.Errorf("Expected key %q, got %q", tc.expectedKey, keys[len(keys)-1])
+			}
+		})
+	}
+}
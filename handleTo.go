@@ -1,16 +1,10 @@
 package bodyrest
 
 import (
-	"encoding/json"
 	"log"
-	"mime/multipart"
 	"net/http"
 	"reflect"
-	"strconv"
-	"strings"
 	"sync"
-
-	"github.com/go-chi/chi/v5"
 )
 
 var once sync.Once
@@ -28,7 +22,7 @@ func SetRestErrorHandler(errFunc RestErrorFunc) {
 	})
 }
 
-func HandleTo(handlerFunc interface{}) http.HandlerFunc {
+func HandleTo(handlerFunc interface{}, opts ...Option) http.HandlerFunc {
 
 	handlerType := reflect.TypeOf(handlerFunc)
 	if handlerType.Kind() != reflect.Func {
@@ -39,244 +33,125 @@ func HandleTo(handlerFunc interface{}) http.HandlerFunc {
 		log.Fatal("http.HandlerFunc is not a valid parameter, use interface function instead")
 	}
 
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		handlerType := reflect.TypeOf(handlerFunc)
-		if handlerType.Kind() != reflect.Func {
-			log.Println("handler is not a function")
-			http.Error(w, defaultResponse, http.StatusInternalServerError)
-			return
-		}
-
-		if handlerType.NumIn() <= 0 {
-			handlerValue := reflect.ValueOf(handlerFunc)
+	if err := checkProvidedParams(handlerType); err != nil {
+		log.Fatal(err)
+	}
 
-			results := handlerValue.Call([]reflect.Value{})
-			if len(results) != 1 {
-				log.Println("handler does not return exactly one value")
-				if restErrorFunc != nil {
-					restErrorFunc(w, r, http.StatusInternalServerError)
-					return
-				}
+	form := resultFormOf(handlerType)
+	if form == resultInvalid {
+		log.Fatalf("handler %s has unsupported return values", handlerType)
+	}
 
-				http.Error(w, defaultResponse, http.StatusInternalServerError)
-				return
-			}
+	cfg := newRouteConfig(opts)
+	if cfg.handlerTimeout > 0 && (form == resultHandler || form == resultHandlerError) {
+		log.Fatalf("handler %s returns an http.Handler, which WithHandlerTimeout cannot bound", handlerType)
+	}
+	if cfg.coalescing && (form == resultHandler || form == resultHandlerError) {
+		log.Fatalf("handler %s returns an http.Handler, which WithCoalescing cannot share", handlerType)
+	}
+	if cfg.coalescing {
+		if err := checkKeyableParams(handlerType); err != nil {
+			log.Fatalf("cannot coalesce: %v", err)
+		}
+	}
 
-			handler, ok := results[0].Interface().(http.HandlerFunc)
-			if !ok {
-				log.Println("handler does not return http.HandlerFunc")
-				if restErrorFunc != nil {
-					restErrorFunc(w, r, http.StatusInternalServerError)
-					return
-				}
+	plan := newBindPlan(handlerType, handlerType.NumIn())
+	invoke := newInvoker(reflect.ValueOf(handlerFunc), cfg, plan)
+	responseType := declaredResponseType(handlerType, form, cfg)
 
-				http.Error(w, defaultResponse, http.StatusInternalServerError)
-				return
-			}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withRouteName(r, cfg.routeName)
+		w, audit := startAudit(w, r)
+		defer audit.finish(plan)
+		w, reqLog := startRequestLog(w, r, cfg)
+		defer reqLog.finish(plan)
 
-			handler.ServeHTTP(w, r)
+		finish, ok := admitRequest(w, r)
+		if !ok {
 			return
 		}
+		defer finish()
 
-		if (r.Method == http.MethodPost ||
-			r.Method == http.MethodPut ||
-			r.Method == http.MethodPatch) &&
-			(r.Body == nil || r.ContentLength == 0) {
-			log.Printf("request body is empty\n")
-			if restErrorFunc != nil {
-				restErrorFunc(w, r, http.StatusBadRequest)
-				return
-			}
-
-			http.Error(w, defaultResponse, http.StatusBadRequest)
+		if !featureEnabled(w, r, cfg) {
 			return
 		}
 
-		// TODO: extract to check path and handler params on handler definition
-		var handlerArgsToCall []reflect.Value = make([]reflect.Value, handlerType.NumIn())
-		lastInspectedPathPartIndex := -1
-		hasBodyStructParsed := false
-		for i := 0; i < handlerType.NumIn(); i++ {
-			paramType := handlerType.In(i)
-			paramValue := reflect.New(paramType)
-
-			if paramType.Kind() == reflect.Struct {
-				if hasBodyStructParsed {
-					log.Println("got more than one body struct")
-					if restErrorFunc != nil {
-						restErrorFunc(w, r, http.StatusBadRequest)
-						return
-					}
-
-					http.Error(w, defaultResponse, http.StatusBadRequest)
-					return
-				}
-
-				if paramType == reflect.TypeOf(multipart.Form{}) {
-					err := r.ParseMultipartForm(32 << 20)
-					if err != nil {
-						log.Printf("failed to parse multipart form: %v\n", err)
-						if restErrorFunc != nil {
-							restErrorFunc(w, r, http.StatusBadRequest)
-							return
-						}
-					}
-
-					paramValue.Elem().Set(reflect.ValueOf(*r.MultipartForm))
-
-				} else {
-					err := json.NewDecoder(r.Body).Decode(paramValue.Interface())
-					if err != nil {
-						log.Printf("failed to parse request body: %v\n", err)
-						if restErrorFunc != nil {
-							restErrorFunc(w, r, http.StatusBadRequest)
-							return
-						}
-
-						http.Error(w, defaultResponse, http.StatusBadRequest)
-						return
-					}
-
-					valid := areRequiredFieldsValid(paramValue.Interface())
-					if !valid {
-						log.Println("required fields are not valid")
-						if restErrorFunc != nil {
-							restErrorFunc(w, r, http.StatusBadRequest)
-							return
-						}
-
-						http.Error(w, defaultResponse, http.StatusBadRequest)
-						return
-					}
-				}
-
-				hasBodyStructParsed = true
-				handlerArgsToCall[i] = paramValue.Elem()
-			} else {
-				routePattern := chi.RouteContext(r.Context()).RoutePattern()
-
-				pathParts := strings.Split(r.URL.Path, "/")
-				patternParts := strings.Split(routePattern, "/")
-
-				for idx, part := range patternParts {
-					if strings.Contains(part, "{") && strings.Contains(part, "}") && idx > lastInspectedPathPartIndex {
-						var pVal interface{}
-						var convErr error
-
-						switch paramType.Kind() {
-						case reflect.Int:
-							pVal, convErr = strconv.Atoi(pathParts[idx])
-						case reflect.String:
-							pVal = pathParts[idx]
-						case reflect.Bool:
-							pVal, convErr = strconv.ParseBool(pathParts[idx])
-						case reflect.Float64:
-							pVal, convErr = strconv.ParseFloat(pathParts[idx], 64)
-						}
-						if convErr != nil {
-							log.Printf("failed to parse path param under index %d: %v\n", idx, convErr)
-							if restErrorFunc != nil {
-								restErrorFunc(w, r, http.StatusBadRequest)
-								return
-							}
-							http.Error(w, defaultResponse, http.StatusBadRequest)
-							return
-						}
-
-						paramValue.Elem().Set(reflect.ValueOf(pVal))
-						handlerArgsToCall[i] = paramValue.Elem()
-
-						lastInspectedPathPartIndex = idx
-						break
-					}
-				}
+		if cfg.circuitBreaker != nil {
+			var done func()
+			var allowed bool
+			w, done, allowed = enterCircuit(w, r, cfg.circuitBreaker)
+			if !allowed {
+				return
 			}
+			defer done()
 		}
 
-		handlerValue := reflect.ValueOf(handlerFunc)
-
-		if handlerType.NumIn() != len(handlerArgsToCall) {
-			log.Printf("got %d arguments, expected %d\n", len(handlerArgsToCall), handlerType.NumIn())
-			if restErrorFunc != nil {
-				restErrorFunc(w, r, http.StatusBadRequest)
-				return
-			}
+		defer recoverHandler(w, r)
 
-			http.Error(w, defaultResponse, http.StatusBadRequest)
+		if _, err := requestedFields(r, cfg); err != nil {
+			log.Printf("invalid fields selection: %v\n", err)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		zeroValueArguments := false
-		for i := 0; i < handlerType.NumIn(); i++ {
-			if !handlerArgsToCall[i].IsValid() {
-				zeroValueArguments = true
-				break
-			}
+		if _, err := requestedView(r, cfg); err != nil {
+			log.Printf("invalid view selection: %v\n", err)
+			writeError(w, r, http.StatusBadRequest, err)
+			return
 		}
 
-		if zeroValueArguments {
-			log.Println("handler has zero value arguments")
-			if restErrorFunc != nil {
-				restErrorFunc(w, r, http.StatusBadRequest)
-				return
-			}
-
-			http.Error(w, defaultResponse, http.StatusBadRequest)
+		handlerArgsToCall, ok := bindArgs(w, r, cfg, plan)
+		if !ok {
+			return
+		}
+		r, owner := withArgsOwner(r, handlerArgsToCall)
+		defer owner.releaseArgs()
+		audit.setArgs(handlerArgsToCall)
+		reqLog.setArgs(handlerArgsToCall)
+		r = withPrincipalRoles(r, plan, handlerArgsToCall)
+
+		if err := runGates(r, cfg, handlerArgsToCall); err != nil {
+			log.Printf("request rejected by gate: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
 			return
 		}
-		results := handlerValue.Call(handlerArgsToCall)
-
-		if len(results) != 1 {
-			log.Println("handler does not return exactly one value")
-			if restErrorFunc != nil {
-				restErrorFunc(w, r, http.StatusInternalServerError)
-				return
-			}
 
-			http.Error(w, defaultResponse, http.StatusInternalServerError)
+		if err := authorize(r, cfg, plan, handlerArgsToCall); err != nil {
+			log.Printf("request not authorized: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
 			return
 		}
 
-		handler, ok := results[0].Interface().(http.HandlerFunc)
-		if !ok {
-			log.Println("handler does not return http.HandlerFunc")
-			if restErrorFunc != nil {
-				restErrorFunc(w, r, http.StatusInternalServerError)
+		r, hooks := withResponseHooks(r)
+		defer hooks.run()
+		r, provided := withProvidedArgs(r)
+		defer provided.abort()
+
+		respond := func(w http.ResponseWriter) {
+			if !plan.providesParams() {
+				invokeHandler(w, r, cfg, form, handlerType, invoke, handlerArgsToCall)
 				return
 			}
 
-			http.Error(w, defaultResponse, http.StatusInternalServerError)
+			pw := newProvidedWriter(w, r, provided, form)
+			invokeHandler(pw, r, cfg, form, handlerType, invoke, handlerArgsToCall)
+			pw.finish()
+		}
+		if responseValidation != ResponseValidationOff && responseType != nil {
+			respond = validatingResponse(r, responseType, respond)
+		}
+
+		if cfg.responseCache != nil && r.Method == http.MethodGet {
+			cfg.responseCache.serve(w, r, plan, handlerArgsToCall, respond)
 			return
 		}
 
-		handler.ServeHTTP(w, r)
+		respond(w)
 	})
 }
 
 func areRequiredFieldsValid(obj interface{}) bool {
-	value := reflect.ValueOf(obj)
-	if value.Kind() == reflect.Ptr {
-		value = value.Elem()
-	}
-
-	if value.Kind() != reflect.Struct {
-		return false
-	}
-
-	for i := 0; i < value.NumField(); i++ {
-		field := value.Type().Field(i)
-		fieldValue := value.Field(i)
-
-		tag := field.Tag.Get("json")
-
-		if tag != "" && tag != "-" && !strings.Contains(tag, "omitempty") {
-			if isFieldEmpty(fieldValue) {
-				return false
-			}
-		}
-	}
-
-	return true
+	return validateRequiredFields(obj) == nil
 }
 
 func isFieldEmpty(field reflect.Value) bool {
@@ -3,9 +3,12 @@ package bodyrest
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -33,6 +36,16 @@ func (h *testHandler) testPostWithParamsAndBody(id int, req testHandlerRequest)
 	}
 }
 
+type testStatusHandler int
+
+func (s testStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(int(s))
+}
+
+func (h *testHandler) testPostReturningHandler(req testHandlerRequest) http.Handler {
+	return testStatusHandler(http.StatusAccepted)
+}
+
 func (h *testHandler) wrongTestPostWithZeroParams() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -155,6 +168,15 @@ func TestHandleTo(t *testing.T) {
 			pattern:        "/test/{id}",
 			path:           "/test/1",
 		},
+		{
+			name:           "Valid JSON payload to handler returning http.Handler",
+			jsonPayload:    `{"message":"Hello", "code": 200, "messagePtr": "Hello", "codePtr": 200}`,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   ``,
+			handler:        testHandler.testPostReturningHandler,
+			pattern:        "/test",
+			path:           "/test",
+		},
 		{
 			name:           "Valid JSON payload to handler with invalid param and body",
 			jsonPayload:    `{"message":"Hello", "code": 200, "messagePtr": "Hello", "codePtr": 200}`,
@@ -191,88 +213,101 @@ func TestHandleTo(t *testing.T) {
 
 func TestWrongHandleTo(t *testing.T) {
 	testHandler := &testHandler{}
+	handlers := map[string]interface{}{
+		"string result": testHandler.wrongTestPost,
+		"no result":     testHandler.wrongTestPostWithNoReturn,
+		"no params":     testHandler.wrongTestPostWithNoReturnAndNoBody,
+	}
+
+	// registration exits the process, so each case registers its handler in
+	// a test binary of its own
+	if name := os.Getenv("BODYREST_WRONG_HANDLER"); name != "" {
+		HandleTo(handlers[name])
+		return
+	}
 
 	testCases := []struct {
-		name           string
-		jsonPayload    string
-		expectedStatus int
-		expectedBody   string
-		pattern        string
-		path           string
+		name string
 	}{
-		{
-			name:           "Invalid handler but with valid JSON payload",
-			jsonPayload:    `{"message":"Hello", "code": 200, "messagePtr": "Hello", "codePtr": 200}`,
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   fmt.Sprintf(`{"message":"%s"}`, ErrHttpInternalErrorText),
-			pattern:        "/test",
-			path:           "/test",
-		},
+		{name: "string result"},
+		{name: "no result"},
+		{name: "no params"},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			req, err := http.NewRequest("POST", tc.path, bytes.NewBufferString(tc.jsonPayload))
-			if err != nil {
-				t.Fatal(err)
-			}
+			cmd := exec.Command(os.Args[0], "-test.run=^TestWrongHandleTo$")
+			cmd.Env = append(os.Environ(), "BODYREST_WRONG_HANDLER="+tc.name)
+			output, err := cmd.CombinedOutput()
 
-			w := httptest.NewRecorder()
-			r := chi.NewRouter()
-			r.Post(tc.pattern, HandleTo(testHandler.wrongTestPost))
-			r.ServeHTTP(w, req)
-
-			if w.Code != tc.expectedStatus {
-				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("Expected registration to fail, got %v", err)
 			}
-
-			if strings.TrimSpace(w.Body.String()) != strings.TrimSpace(tc.expectedBody) {
-				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			if !strings.Contains(string(output), "unsupported return values") {
+				t.Errorf("Expected unsupported return values to be reported, got %s", output)
 			}
 		})
 	}
+}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			req, err := http.NewRequest("POST", tc.path, bytes.NewBufferString(tc.jsonPayload))
-			if err != nil {
-				t.Fatal(err)
-			}
+var errTestConflict = errors.New("conflict")
 
-			w := httptest.NewRecorder()
-			r := chi.NewRouter()
-			r.Post(tc.pattern, HandleTo(testHandler.wrongTestPostWithNoReturn))
-			r.ServeHTTP(w, req)
+func (h *testHandler) testPostWithError(req testHandlerRequest) (http.HandlerFunc, error) {
+	if req.Code == http.StatusConflict {
+		return nil, errTestConflict
+	}
+	if req.Code == http.StatusInternalServerError {
+		return nil, errors.New("unexpected")
+	}
 
-			if w.Code != tc.expectedStatus {
-				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
-			}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, nil
+}
 
-			if strings.TrimSpace(w.Body.String()) != strings.TrimSpace(tc.expectedBody) {
-				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
-			}
-		})
+func TestHandleToWithError(t *testing.T) {
+	RegisterError(errTestConflict, http.StatusConflict)
+
+	testHandler := &testHandler{}
+
+	testCases := []struct {
+		name           string
+		jsonPayload    string
+		expectedStatus int
+	}{
+		{
+			name:           "Handler returns no error",
+			jsonPayload:    `{"message":"Hello", "code": 200, "messagePtr": "Hello", "codePtr": 200}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Handler returns registered error",
+			jsonPayload:    `{"message":"Hello", "code": 409, "messagePtr": "Hello", "codePtr": 200}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Handler returns unregistered error",
+			jsonPayload:    `{"message":"Hello", "code": 500, "messagePtr": "Hello", "codePtr": 200}`,
+			expectedStatus: http.StatusInternalServerError,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			req, err := http.NewRequest("POST", tc.path, bytes.NewBufferString(tc.jsonPayload))
+			req, err := http.NewRequest("POST", "/test", bytes.NewBufferString(tc.jsonPayload))
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			w := httptest.NewRecorder()
 			r := chi.NewRouter()
-			r.Post(tc.pattern, HandleTo(testHandler.wrongTestPostWithNoReturnAndNoBody))
+			r.Post("/test", HandleTo(testHandler.testPostWithError))
+			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
 			if w.Code != tc.expectedStatus {
 				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
 			}
-
-			if strings.TrimSpace(w.Body.String()) != strings.TrimSpace(tc.expectedBody) {
-				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
-			}
 		})
 	}
 }
@@ -0,0 +1,88 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+)
+
+// HealthStatus is the state reported by a health check.
+type HealthStatus string
+
+const (
+	HealthUp       HealthStatus = "up"
+	HealthDegraded HealthStatus = "degraded"
+	HealthDown     HealthStatus = "down"
+)
+
+// HealthCheck is a named probe of a dependency. Checks marked Liveness are
+// run by /healthz as well as /readyz; the others only decide readiness.
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) HealthStatus
+	Liveness bool
+}
+
+// HealthReport is the body of the health endpoints: the overall status and
+// the status of each check run.
+type HealthReport struct {
+	Status HealthStatus            `json:"status"`
+	Checks map[string]HealthStatus `json:"checks,omitempty"`
+}
+
+// Health registers GET /healthz and /readyz on rt. Both answer 200 with a
+// HealthReport while no check is down and 503 otherwise, encoded like any
+// other bodyrest response. /healthz only runs the Liveness checks.
+func Health(rt *Router, checks ...HealthCheck) {
+	var liveness []HealthCheck
+	for _, check := range checks {
+		if check.Liveness {
+			liveness = append(liveness, check)
+		}
+	}
+
+	rt.register(http.MethodGet, "/healthz", healthHandler(liveness), RouteInfo{Method: http.MethodGet, Pattern: "/healthz"})
+	rt.register(http.MethodGet, "/readyz", healthHandler(checks), RouteInfo{Method: http.MethodGet, Pattern: "/readyz"})
+}
+
+func healthHandler(checks []HealthCheck) http.HandlerFunc {
+	cfg := newRouteConfig(nil)
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := runHealthChecks(r.Context(), checks)
+
+		status := http.StatusOK
+		if report.Status == HealthDown {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		writeResponse(w, r, cfg, Response{Status: status, Body: report})
+	}
+}
+
+// runHealthChecks runs checks in order. The report is down if any check is
+// down, degraded if any is degraded and up otherwise; a check returning an
+// unknown status counts as down.
+func runHealthChecks(ctx context.Context, checks []HealthCheck) HealthReport {
+	report := HealthReport{Status: HealthUp}
+	if len(checks) > 0 {
+		report.Checks = make(map[string]HealthStatus, len(checks))
+	}
+
+	for _, check := range checks {
+		status := check.Check(ctx)
+		switch status {
+		case HealthUp:
+		case HealthDegraded:
+			if report.Status == HealthUp {
+				report.Status = HealthDegraded
+			}
+		default:
+			status = HealthDown
+			report.Status = HealthDown
+		}
+
+		report.Checks[check.Name] = status
+	}
+
+	return report
+}
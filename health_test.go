@@ -0,0 +1,92 @@
+package bodyrest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	healthCheck := func(status HealthStatus) func(context.Context) HealthStatus {
+		return func(context.Context) HealthStatus { return status }
+	}
+
+	testCases := []struct {
+		name           string
+		checks         []HealthCheck
+		path           string
+		expectedStatus int
+		expectedReport HealthReport
+	}{
+		{
+			name:           "no checks",
+			path:           "/healthz",
+			expectedStatus: http.StatusOK,
+			expectedReport: HealthReport{Status: HealthUp},
+		},
+		{
+			name: "liveness skips readiness checks",
+			checks: []HealthCheck{
+				{Name: "loop", Check: healthCheck(HealthUp), Liveness: true},
+				{Name: "db", Check: healthCheck(HealthDown)},
+			},
+			path:           "/healthz",
+			expectedStatus: http.StatusOK,
+			expectedReport: HealthReport{Status: HealthUp, Checks: map[string]HealthStatus{"loop": HealthUp}},
+		},
+		{
+			name: "not ready",
+			checks: []HealthCheck{
+				{Name: "loop", Check: healthCheck(HealthUp), Liveness: true},
+				{Name: "db", Check: healthCheck(HealthDown)},
+			},
+			path:           "/readyz",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: HealthReport{Status: HealthDown, Checks: map[string]HealthStatus{"loop": HealthUp, "db": HealthDown}},
+		},
+		{
+			name: "degraded is ready",
+			checks: []HealthCheck{
+				{Name: "cache", Check: healthCheck(HealthDegraded)},
+			},
+			path:           "/readyz",
+			expectedStatus: http.StatusOK,
+			expectedReport: HealthReport{Status: HealthDegraded, Checks: map[string]HealthStatus{"cache": HealthDegraded}},
+		},
+		{
+			name: "unknown status is down",
+			checks: []HealthCheck{
+				{Name: "queue", Check: healthCheck("")},
+			},
+			path:           "/readyz",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: HealthReport{Status: HealthDown, Checks: map[string]HealthStatus{"queue": HealthDown}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRouter(nil)
+			Health(rt, tc.checks...)
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			var report HealthReport
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("Failed to decode report: %v", err)
+			}
+			if !reflect.DeepEqual(report, tc.expectedReport) {
+				t.Errorf("Expected %+v, got %+v", tc.expectedReport, report)
+			}
+		})
+	}
+}
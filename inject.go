@@ -0,0 +1,58 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+// ErrUnauthorized is returned when a request lacks valid credentials. It is
+// mapped to 401.
+var ErrUnauthorized = errors.New("unauthorized")
+
+func init() {
+	RegisterError(ErrUnauthorized, http.StatusUnauthorized)
+
+	registerInjector(typeOf[context.Context](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(r.Context()), nil
+	})
+
+	registerInjector(typeOf[url.Values](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(r.URL.Query()), nil
+	})
+
+	registerInjector(typeOf[http.Header](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(r.Header.Clone()), nil
+	})
+}
+
+// injectorFunc produces the value of an injected handler parameter from the
+// request. Errors are mapped through the error registry.
+type injectorFunc func(r *http.Request) (reflect.Value, error)
+
+var (
+	injectorsMu sync.RWMutex
+	injectors   = map[reflect.Type]injectorFunc{}
+)
+
+func registerInjector(t reflect.Type, inject injectorFunc) {
+	injectorsMu.Lock()
+	defer injectorsMu.Unlock()
+
+	injectors[t] = inject
+}
+
+func injectorFor(t reflect.Type) (injectorFunc, bool) {
+	injectorsMu.RLock()
+	defer injectorsMu.RUnlock()
+
+	inject, ok := injectors[t]
+	return inject, ok
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
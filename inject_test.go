@@ -0,0 +1,38 @@
+package bodyrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRawSetParameters(t *testing.T) {
+	handler := func(query url.Values, header http.Header, u testUser) (string, error) {
+		return query.Get("filter") + ":" + header.Get("X-Trace") + ":" + u.Name, nil
+	}
+
+	req, err := http.NewRequest("POST", "/users?filter=active", bytes.NewBufferString(`{"name":"john"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Trace", "abc")
+
+	r := chi.NewRouter()
+	r.Post("/users", HandleTo(handler))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	expectedBody := `"active:abc:john"`
+	if strings.TrimSpace(w.Body.String()) != expectedBody {
+		t.Errorf("Expected body %s, got %s", expectedBody, w.Body.String())
+	}
+}
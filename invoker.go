@@ -0,0 +1,192 @@
+package bodyrest
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Invoker calls a typed handler with its bound arguments. It returns the
+// handler's results without the trailing error, and that error separately.
+type Invoker func(r *http.Request, args []any) ([]any, error)
+
+// WithWrapper decorates the call of the typed handler, after binding and
+// before the response is written, e.g. to run it in a transaction or check
+// the bound arguments. Wrappers run in the order given, the first one
+// outermost. An error returned by a wrapper without calling next goes
+// through the error registry like a handler error.
+func WithWrapper(wrap func(next Invoker) Invoker) Option {
+	return func(cfg *routeConfig) {
+		cfg.wrappers = append(cfg.wrappers, wrap)
+	}
+}
+
+// newInvoker returns the Invoker calling handler, decorated by coalescing,
+// the route wrappers and, outermost, by the lifecycle of provided params.
+func newInvoker(handler reflect.Value, cfg *routeConfig, plan *bindPlan) Invoker {
+	handlerType := handler.Type()
+	hasError := handlerType.NumOut() > 0 && handlerType.Out(handlerType.NumOut()-1) == errorType
+
+	invoke := Invoker(func(r *http.Request, args []any) ([]any, error) {
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			in[i] = valueOrZero(arg, handlerType.In(i))
+		}
+
+		out := handler.Call(in)
+
+		var err error
+		if hasError {
+			err, _ = out[len(out)-1].Interface().(error)
+			out = out[:len(out)-1]
+		}
+
+		results := make([]any, len(out))
+		for i, v := range out {
+			results[i] = v.Interface()
+		}
+		return results, err
+	})
+
+	invoke = detectSlow(cfg, plan)(invoke)
+	if cfg.coalescing {
+		invoke = coalesce(plan)(invoke)
+	}
+	for i := len(cfg.wrappers) - 1; i >= 0; i-- {
+		invoke = cfg.wrappers[i](invoke)
+	}
+
+	plan.provides = true
+	invoke = provideArgs(plan)(invoke)
+
+	return invoke
+}
+
+// invokeHandler calls the handler through invoke and writes its results. On
+// an error without handler results, e.g. one returned by a wrapper, it
+// writes the error response itself.
+func invokeHandler(w http.ResponseWriter, r *http.Request, cfg *routeConfig, form resultForm, handlerType reflect.Type, invoke Invoker, args []reflect.Value) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Interface()
+	}
+
+	results, err := invoke(r, values)
+
+	hasError := handlerType.NumOut() > 0 && handlerType.Out(handlerType.NumOut()-1) == errorType
+	if err != nil && (!hasError || len(results) != handlerType.NumOut()-1) {
+		log.Printf("handler returned error: %v\n", err)
+		writeError(w, r, statusFromError(err), err)
+		return
+	}
+
+	out := make([]reflect.Value, 0, handlerType.NumOut())
+	for i, result := range results {
+		if i >= handlerType.NumOut() {
+			break
+		}
+		out = append(out, valueOrZero(result, handlerType.Out(i)))
+	}
+	if hasError {
+		out = append(out, valueOrZero(err, errorType))
+	}
+
+	if len(out) != handlerType.NumOut() {
+		log.Printf("wrapper returned %d results for handler %s\n", len(results), handlerType)
+		writeError(w, r, http.StatusInternalServerError, nil)
+		return
+	}
+
+	writeResults(w, r, cfg, form, out)
+}
+
+type responseHooksContextKey struct{}
+
+// responseHooks are run by HandleTo once the response of a request is
+// written, for wrappers whose work must cover the response, e.g. the one
+// written by an http.Handler returned by the handler.
+type responseHooks struct {
+	mu       sync.Mutex
+	hooks    []func()
+	detached bool
+	done     bool
+}
+
+// withResponseHooks returns r carrying the responseHooks wrappers add to
+// with afterResponse.
+func withResponseHooks(r *http.Request) (*http.Request, *responseHooks) {
+	hooks := &responseHooks{}
+	return r.WithContext(context.WithValue(r.Context(), responseHooksContextKey{}, hooks)), hooks
+}
+
+// afterResponse runs fn once the response of r is written, or right away
+// when r carries no responseHooks.
+func afterResponse(r *http.Request, fn func()) {
+	hooks, ok := r.Context().Value(responseHooksContextKey{}).(*responseHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	if hooks.done {
+		hooks.mu.Unlock()
+		fn()
+		return
+	}
+	hooks.hooks = append(hooks.hooks, fn)
+	hooks.mu.Unlock()
+}
+
+// run runs the hooks, last added first, unless they have been detached.
+func (h *responseHooks) run() {
+	h.mu.Lock()
+	detached := h.detached
+	h.mu.Unlock()
+
+	if !detached {
+		h.finish()
+	}
+}
+
+// detach makes run a no-op and returns the function running the hooks, for
+// a handler still running after a timeout.
+func (h *responseHooks) detach() func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.detached = true
+	return h.finish
+}
+
+func (h *responseHooks) finish() {
+	h.mu.Lock()
+	if h.done {
+		h.mu.Unlock()
+		return
+	}
+	h.done = true
+	hooks := h.hooks
+	h.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// valueOrZero returns v as a value of type t, or the zero value of t if v is
+// nil.
+func valueOrZero(v any, t reflect.Type) reflect.Value {
+	if v == nil {
+		return reflect.Zero(t)
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Type() != t && value.Type().ConvertibleTo(t) {
+		return value.Convert(t)
+	}
+
+	return value
+}
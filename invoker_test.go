@@ -0,0 +1,109 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errTestForbidden = errors.New("forbidden")
+
+func TestWithWrapper(t *testing.T) {
+	RegisterError(errTestForbidden, http.StatusForbidden)
+
+	var calls []string
+	trace := func(name string) func(next Invoker) Invoker {
+		return func(next Invoker) Invoker {
+			return func(r *http.Request, args []any) ([]any, error) {
+				calls = append(calls, name+" before")
+				results, err := next(r, args)
+				calls = append(calls, name+" after")
+				return results, err
+			}
+		}
+	}
+
+	rejectAdmin := func(next Invoker) Invoker {
+		return func(r *http.Request, args []any) ([]any, error) {
+			if args[0].(testUser).Name == "admin" {
+				return nil, errTestForbidden
+			}
+			return next(r, args)
+		}
+	}
+
+	rename := func(next Invoker) Invoker {
+		return func(r *http.Request, args []any) ([]any, error) {
+			user := args[0].(testUser)
+			user.Name = "wrapped " + user.Name
+			return next(r, []any{user})
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		expectedBody  string
+		wrappers      []func(next Invoker) Invoker
+		expectedCalls []string
+	}{
+		{
+			name:          "wrapped call",
+			body:          `{"name":"John"}`,
+			expectedCode:  http.StatusCreated,
+			expectedBody:  `{"name":"wrapped John"}`,
+			wrappers:      []func(next Invoker) Invoker{trace("outer"), rejectAdmin, trace("inner"), rename},
+			expectedCalls: []string{"outer before", "inner before", "inner after", "outer after"},
+		},
+		{
+			name:          "rejected by wrapper",
+			body:          `{"name":"admin"}`,
+			expectedCode:  http.StatusForbidden,
+			wrappers:      []func(next Invoker) Invoker{trace("outer"), rejectAdmin, trace("inner"), rename},
+			expectedCalls: []string{"outer before", "outer after"},
+		},
+		{
+			name:          "handler error",
+			body:          `{"name":"exists"}`,
+			expectedCode:  http.StatusConflict,
+			wrappers:      []func(next Invoker) Invoker{trace("outer"), trace("inner")},
+			expectedCalls: []string{"outer before", "inner before", "inner after", "outer after"},
+		},
+	}
+
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.body))
+
+			var opts []Option
+			for _, wrapper := range tc.wrappers {
+				opts = append(opts, WithWrapper(wrapper))
+			}
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(testCreateUser, opts...))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+
+			if strings.Join(calls, ", ") != strings.Join(tc.expectedCalls, ", ") {
+				t.Errorf("Expected calls %v, got %v", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Job is returned by handlers, e.g. func(req T) (bodyrest.Job, error), that
+// start a long-running operation. bodyrest answers 202 with the pending
+// status of the job and a Location header pointing to its status route.
+type Job struct {
+	ID string
+}
+
+var jobType = typeOf[Job]()
+
+// JobState is the state of an asynchronous job.
+type JobState string
+
+const (
+	JobPending   JobState = "pending"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// JobStatus is the body of the 202 response and of the job status route.
+type JobStatus struct {
+	ID     string   `json:"id"`
+	State  JobState `json:"state"`
+	Result any      `json:"result,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// JobStore looks up the status of jobs for JobStatusHandler. It returns
+// ErrJobNotFound for unknown IDs.
+type JobStore interface {
+	JobStatus(ctx context.Context, id string) (JobStatus, error)
+}
+
+// ErrJobNotFound is returned by a JobStore for unknown jobs. It is mapped to
+// 404.
+var ErrJobNotFound = errors.New("job not found")
+
+func init() {
+	RegisterError(ErrJobNotFound, http.StatusNotFound)
+}
+
+var jobStatusPath = "/jobs/{id}"
+
+// SetJobStatusPath sets the path of the job status route used for the
+// Location header of accepted jobs, "/jobs/{id}" by default. {id} is
+// replaced by the job ID.
+func SetJobStatusPath(path string) {
+	jobStatusPath = path
+}
+
+func acceptedJob(job Job) Response {
+	return Response{
+		Status: http.StatusAccepted,
+		Header: http.Header{"Location": []string{strings.ReplaceAll(jobStatusPath, "{id}", url.PathEscape(job.ID))}},
+		Body:   JobStatus{ID: job.ID, State: JobPending},
+	}
+}
+
+// JobStatusHandler serves the status of the job whose ID is the first path
+// param, e.g. r.Get("/jobs/{id}", bodyrest.JobStatusHandler(store)).
+func JobStatusHandler(store JobStore) http.HandlerFunc {
+	cfg := newRouteConfig(nil)
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok, err := routePathParam(r, 0)
+		if errors.Is(err, errNoRouteContext) {
+			log.Printf("failed to read job id: %v\n", err)
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if err != nil {
+			log.Printf("failed to decode job id: %v\n", err)
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if !ok {
+			writeError(w, r, http.StatusNotFound, ErrJobNotFound)
+			return
+		}
+
+		status, err := store.JobStatus(r.Context(), id)
+		if err != nil {
+			log.Printf("failed to get job status: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		writeResponse(w, r, cfg, Response{Body: status})
+	}
+}
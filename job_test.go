@@ -0,0 +1,74 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testJobStore map[string]JobStatus
+
+func (s testJobStore) JobStatus(ctx context.Context, id string) (JobStatus, error) {
+	status, ok := s[id]
+	if !ok {
+		return JobStatus{}, ErrJobNotFound
+	}
+
+	return status, nil
+}
+
+func TestJobs(t *testing.T) {
+	store := testJobStore{
+		"export-1": {ID: "export-1", State: JobSucceeded, Result: "users.csv"},
+	}
+
+	r := chi.NewRouter()
+	r.Post("/exports", HandleTo(func(u testUser) (Job, error) {
+		if u.Name == "exists" {
+			return Job{}, errTestUserExists
+		}
+		return Job{ID: "export-2"}, nil
+	}))
+	r.Get("/jobs/{id}", JobStatusHandler(store))
+
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	testCases := []struct {
+		name             string
+		method           string
+		url              string
+		body             string
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{name: "accepted", method: "POST", url: "/exports", body: `{"name":"john"}`, expectedStatus: http.StatusAccepted, expectedBody: `{"id":"export-2","state":"pending"}`, expectedLocation: "/jobs/export-2"},
+		{name: "rejected", method: "POST", url: "/exports", body: `{"name":"exists"}`, expectedStatus: http.StatusConflict},
+		{name: "status", method: "GET", url: "/jobs/export-1", expectedStatus: http.StatusOK, expectedBody: `{"id":"export-1","state":"succeeded","result":"users.csv"}`},
+		{name: "unknown job", method: "GET", url: "/jobs/export-3", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.Join(strings.Fields(w.Body.String()), "") != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+
+			if location := w.Header().Get("Location"); location != tc.expectedLocation {
+				t.Errorf("Expected Location %q, got %q", tc.expectedLocation, location)
+			}
+		})
+	}
+}
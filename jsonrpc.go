@@ -0,0 +1,251 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+const jsonRPCVersion = "2.0"
+
+// JSON-RPC 2.0 error codes.
+const (
+	RPCParseError     = -32700
+	RPCInvalidRequest = -32600
+	RPCMethodNotFound = -32601
+	RPCInvalidParams  = -32602
+	RPCInternalError  = -32603
+	RPCServerError    = -32000
+)
+
+// JSONRPC exposes bodyrest-style handlers as JSON-RPC 2.0 methods over a
+// single endpoint. Register methods and mount it as an http.Handler.
+type JSONRPC struct {
+	mu      sync.RWMutex
+	methods map[string]reflect.Value
+	cfg     *routeConfig
+}
+
+// NewJSONRPC returns an empty JSON-RPC endpoint. Params are decoded with the
+// body options given, e.g. WithJSONLimits or WithRejectDuplicateKeys.
+func NewJSONRPC(opts ...Option) *JSONRPC {
+	return &JSONRPC{methods: map[string]reflect.Value{}, cfg: newRouteConfig(opts)}
+}
+
+// Register adds a method. The handler takes no parameters or a single
+// struct bound from the request params like a request body, and returns a
+// value and an error, e.g. func(req T) (Res, error). Invalid params are
+// reported with their violations, handler errors with the status mapped by
+// the error registry in the error data and panics as internal errors.
+func (rpc *JSONRPC) Register(method string, handlerFunc interface{}) {
+	handlerType := reflect.TypeOf(handlerFunc)
+	if handlerType.Kind() != reflect.Func {
+		log.Fatal("Handler is not a function")
+	}
+
+	if handlerType.NumIn() > 1 || (handlerType.NumIn() == 1 && handlerType.In(0).Kind() != reflect.Struct) {
+		log.Fatal("JSON-RPC handler must take no parameters or a single struct")
+	}
+
+	if resultFormOf(handlerType) != resultValue {
+		log.Fatal("JSON-RPC handler must return a value and an error")
+	}
+
+	rpc.mu.Lock()
+	defer rpc.mu.Unlock()
+
+	rpc.methods[method] = reflect.ValueOf(handlerFunc)
+}
+
+// RPCError is the error object of a JSON-RPC response.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+var rpcNullID = json.RawMessage("null")
+
+func (rpc *JSONRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	finish, ok := admitRequest(w, r)
+	if !ok {
+		return
+	}
+	defer finish()
+
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeRPC(w, r, rpcFailure(rpcNullID, RPCParseError, "Parse error"))
+		return
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		if resp := rpc.call(w, r, raw); resp != nil {
+			writeRPC(w, r, resp)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(raw, &batch); err != nil {
+		writeRPC(w, r, rpcFailure(rpcNullID, RPCParseError, "Parse error"))
+		return
+	}
+
+	if len(batch) == 0 {
+		writeRPC(w, r, rpcFailure(rpcNullID, RPCInvalidRequest, "Invalid Request"))
+		return
+	}
+
+	responses := []*rpcResponse{}
+	for _, item := range batch {
+		if resp := rpc.call(w, r, item); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeRPC(w, r, responses)
+}
+
+// call runs a single request. It returns nil for notifications.
+func (rpc *JSONRPC) call(w http.ResponseWriter, r *http.Request, raw json.RawMessage) (result *rpcResponse) {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		return rpcFailure(rpcNullID, RPCInvalidRequest, "Invalid Request")
+	}
+
+	id := req.ID
+	notification := id == nil
+	respond := func(resp *rpcResponse) *rpcResponse {
+		if notification {
+			return nil
+		}
+		return resp
+	}
+
+	rpc.mu.RLock()
+	handlerValue, ok := rpc.methods[req.Method]
+	rpc.mu.RUnlock()
+	if !ok {
+		return respond(rpcFailure(id, RPCMethodNotFound, "Method not found"))
+	}
+
+	defer func() {
+		if value := recover(); value != nil {
+			log.Printf("JSON-RPC method %s panicked: %v\n", req.Method, value)
+			result = respond(rpcFailure(id, RPCInternalError, "Internal error"))
+		}
+	}()
+
+	args := []reflect.Value{}
+	if handlerValue.Type().NumIn() == 1 {
+		param, failure := rpc.bindParams(w, r, handlerValue.Type().In(0), req.Params)
+		if failure != nil {
+			failure.ID = id
+			return respond(failure)
+		}
+		args = append(args, param)
+	}
+
+	out := handlerValue.Call(args)
+	if err, _ := out[1].Interface().(error); err != nil {
+		log.Printf("JSON-RPC method %s returned error: %v\n", req.Method, err)
+		status := statusFromError(err)
+		resp := rpcFailure(id, RPCServerError, http.StatusText(status))
+		resp.Error.Data = map[string]int{"status": status}
+		return respond(resp)
+	}
+
+	resp := &rpcResponse{JSONRPC: jsonRPCVersion, ID: id}
+	if !isNilValue(out[0]) {
+		resp.Result = out[0].Interface()
+	}
+	if resp.Result == nil {
+		resp.Result = json.RawMessage("null")
+	}
+
+	return respond(resp)
+}
+
+// bindParams decodes params into a value of t like a request body: with the
+// JSON limits and checks of the endpoint, then validated and completed by
+// AfterBind.
+func (rpc *JSONRPC) bindParams(w http.ResponseWriter, r *http.Request, t reflect.Type, params json.RawMessage) (reflect.Value, *rpcResponse) {
+	param := reflect.New(t)
+	if len(params) == 0 {
+		return param, rpcFailure(nil, RPCInvalidParams, "Invalid params")
+	}
+
+	if err := decodeJSON(w, r, rpc.cfg, params, param.Interface()); err != nil {
+		log.Printf("failed to parse JSON-RPC params: %v\n", err)
+		return param, rpcFailure(nil, RPCInvalidParams, "Invalid params")
+	}
+
+	if err := validateRequiredFields(param.Interface()); err != nil {
+		failure := rpcFailure(nil, RPCInvalidParams, "Invalid params")
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			failure.Error.Data = map[string]any{"violations": validationErr.Violations}
+		}
+		return param, failure
+	}
+
+	var err error
+	if binder, ok := param.Interface().(afterBinder); ok {
+		err = binder.afterBind()
+	}
+	if binder, ok := param.Interface().(AfterBinder); ok && err == nil {
+		err = binder.AfterBind(r)
+	}
+	if err != nil {
+		log.Printf("failed to complete %s: %v\n", t, err)
+		failure := rpcFailure(nil, RPCInvalidParams, "Invalid params")
+		failure.Error.Data = map[string]int{"status": afterBindStatus(err)}
+		return param, failure
+	}
+
+	return param.Elem(), nil
+}
+
+func rpcFailure(id json.RawMessage, code int, message string) *rpcResponse {
+	return &rpcResponse{
+		JSONRPC: jsonRPCVersion,
+		Error:   &RPCError{Code: code, Message: message},
+		ID:      id,
+	}
+}
+
+func writeRPC(w http.ResponseWriter, r *http.Request, body any) {
+	writeResponse(w, r, &routeConfig{}, Response{Status: http.StatusOK, Body: body})
+}
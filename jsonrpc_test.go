@@ -0,0 +1,95 @@
+package bodyrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONRPC(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	rpc := NewJSONRPC(WithRejectDuplicateKeys())
+	rpc.Register("user.import", testImportUser)
+	rpc.Register("user.panic", func(u testUser) (testUser, error) {
+		panic("boom")
+	})
+
+	testCases := []struct {
+		name           string
+		jsonPayload    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Single call",
+			jsonPayload:    `{"jsonrpc":"2.0","method":"user.import","params":{"name":"john"},"id":1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","result":{"name":"JOHN"},"id":1}`,
+		},
+		{
+			name:           "Invalid params",
+			jsonPayload:    `{"jsonrpc":"2.0","method":"user.import","params":{"name":""},"id":"a"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":{"violations":[{"field":"name","message":"is required"}]}},"id":"a"}`,
+		},
+		{
+			name:           "Duplicate keys in params",
+			jsonPayload:    `{"jsonrpc":"2.0","method":"user.import","params":{"name":"john","name":"jo"},"id":4}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":4}`,
+		},
+		{
+			name:           "Panicking call in batch",
+			jsonPayload:    `[{"jsonrpc":"2.0","method":"user.panic","params":{"name":"a"},"id":5},{"jsonrpc":"2.0","method":"user.import","params":{"name":"b"},"id":6}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":5},{"jsonrpc":"2.0","result":{"name":"B"},"id":6}]`,
+		},
+		{
+			name:           "Handler error",
+			jsonPayload:    `{"jsonrpc":"2.0","method":"user.import","params":{"name":"exists"},"id":2}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","error":{"code":-32000,"message":"Conflict","data":{"status":409}},"id":2}`,
+		},
+		{
+			name:           "Batch with notification and unknown method",
+			jsonPayload:    `[{"jsonrpc":"2.0","method":"user.import","params":{"name":"a"}},{"jsonrpc":"2.0","method":"nope","id":3},{"foo":1}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":3},{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}]`,
+		},
+		{
+			name:           "Only notifications",
+			jsonPayload:    `{"jsonrpc":"2.0","method":"user.import","params":{"name":"a"}}`,
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   ``,
+		},
+		{
+			name:           "Parse error",
+			jsonPayload:    `{"jsonrpc":`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/rpc", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			rpc.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
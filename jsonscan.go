@@ -0,0 +1,238 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// JSONLimits bounds the shape of request bodies. Zero fields are unlimited.
+type JSONLimits struct {
+	MaxDepth        int
+	MaxArrayLength  int
+	MaxStringLength int
+	// MaxElements bounds the total number of elements of the slices and
+	// maps decoded into the body struct, at any depth. Unlike the other
+	// limits it is checked after decoding, on the bound value.
+	MaxElements int
+}
+
+// scansRaw reports whether l has limits checked on the raw body.
+func (l JSONLimits) scansRaw() bool {
+	return l.MaxDepth > 0 || l.MaxArrayLength > 0 || l.MaxStringLength > 0
+}
+
+var defaultJSONLimits JSONLimits
+
+// SetJSONLimits sets the limits applied to request bodies of routes that do
+// not set their own with WithJSONLimits.
+func SetJSONLimits(limits JSONLimits) {
+	defaultJSONLimits = limits
+}
+
+// WithJSONLimits sets the limits applied to request bodies of a route. They
+// are checked on the raw body before it is decoded, so oversized payloads
+// are rejected with 400 without being materialized, except MaxElements,
+// which guards handlers iterating the bound collections.
+func WithJSONLimits(limits JSONLimits) Option {
+	return func(cfg *routeConfig) {
+		cfg.jsonLimits = &limits
+	}
+}
+
+func jsonLimitsFor(cfg *routeConfig) JSONLimits {
+	if cfg.jsonLimits != nil {
+		return *cfg.jsonLimits
+	}
+
+	return defaultJSONLimits
+}
+
+// JSONLimitError reports a request body exceeding one of the JSONLimits.
+type JSONLimitError struct {
+	Limit string
+	Path  string
+}
+
+func (e *JSONLimitError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("request body exceeds %s", e.Limit)
+	}
+
+	return fmt.Sprintf("request body exceeds %s at %s", e.Limit, e.Path)
+}
+
+type jsonScope struct {
+	object bool
+	path   string
+	keys   map[string]struct{}
+	key    string
+	index  int
+}
+
+// scanJSON walks data token by token, checking for duplicate keys when
+// dupes is set and enforcing limits.
+func scanJSON(data []byte, dupes bool, limits JSONLimits) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var stack []*jsonScope
+
+	childPath := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			if top.path == "" {
+				return top.key
+			}
+			return top.path + "." + top.key
+		}
+		return fmt.Sprintf("%s[%d]", top.path, top.index)
+	}
+
+	beforeValue := func() error {
+		if len(stack) == 0 {
+			return nil
+		}
+		top := stack[len(stack)-1]
+		if !top.object && limits.MaxArrayLength > 0 && top.index >= limits.MaxArrayLength {
+			return &JSONLimitError{Limit: "maximum array length", Path: top.path}
+		}
+		return nil
+	}
+
+	afterValue := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.key = ""
+		} else {
+			top.index++
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if s, ok := token.(string); ok && limits.MaxStringLength > 0 && len(s) > limits.MaxStringLength {
+			return &JSONLimitError{Limit: "maximum string length", Path: childPath()}
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if err := beforeValue(); err != nil {
+					return err
+				}
+				if limits.MaxDepth > 0 && len(stack) >= limits.MaxDepth {
+					return &JSONLimitError{Limit: "maximum depth", Path: childPath()}
+				}
+				stack = append(stack, &jsonScope{object: t == '{', path: childPath(), keys: map[string]struct{}{}})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				afterValue()
+			}
+		case string:
+			if len(stack) > 0 && stack[len(stack)-1].object && stack[len(stack)-1].key == "" {
+				top := stack[len(stack)-1]
+				if _, ok := top.keys[t]; ok && dupes {
+					return &DuplicateKeyError{Key: t, Path: top.path}
+				}
+				top.keys[t] = struct{}{}
+				top.key = t
+				continue
+			}
+			if err := beforeValue(); err != nil {
+				return err
+			}
+			afterValue()
+		default:
+			if err := beforeValue(); err != nil {
+				return err
+			}
+			afterValue()
+		}
+	}
+}
+
+// checkDecodedElements returns a JSONLimitError when the slices and maps of
+// v hold more than max elements in total.
+func checkDecodedElements(v reflect.Value, max int) error {
+	remaining := max
+	return countElements(v, "", &remaining)
+}
+
+func countElements(v reflect.Value, path string, remaining *int) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return countElements(v.Elem(), path, remaining)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := path
+			if !field.Anonymous {
+				fieldPath = joinFieldPath(path, jsonFieldName(field))
+			}
+			if err := countElements(v.Field(i), fieldPath, remaining); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Map:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+
+		*remaining -= v.Len()
+		if *remaining < 0 {
+			return &JSONLimitError{Limit: "maximum elements", Path: path}
+		}
+
+		if v.Kind() == reflect.Map {
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := countElements(iter.Value(), joinFieldPath(path, fmt.Sprint(iter.Key().Interface())), remaining); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := countElements(v.Index(i), fmt.Sprintf("%s[%d]", path, i), remaining); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// joinFieldPath appends the field name to path, as in JSONLimitError paths.
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,107 @@
+package bodyrest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestScanJSONLimits(t *testing.T) {
+	limits := JSONLimits{MaxDepth: 2, MaxArrayLength: 2, MaxStringLength: 5}
+
+	testCases := []struct {
+		name        string
+		jsonPayload string
+		expectedErr string
+	}{
+		{name: "Within limits", jsonPayload: `{"a":[1,2],"b":{"c":"hello"}}`},
+		{name: "Too deep", jsonPayload: `{"a":{"b":{"c":1}}}`, expectedErr: "request body exceeds maximum depth at a.b"},
+		{name: "Array too long", jsonPayload: `{"a":[1,2,3]}`, expectedErr: "request body exceeds maximum array length at a"},
+		{name: "Array of objects too long", jsonPayload: `[{},{},{}]`, expectedErr: "request body exceeds maximum array length"},
+		{name: "String too long", jsonPayload: `{"a":"too long"}`, expectedErr: "request body exceeds maximum string length at a"},
+		{name: "Key too long", jsonPayload: `{"toolong":1}`, expectedErr: "request body exceeds maximum string length"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := scanJSON([]byte(tc.jsonPayload), false, limits)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var limitErr *JSONLimitError
+			if !errors.As(err, &limitErr) || err.Error() != tc.expectedErr {
+				t.Errorf("Expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestWithJSONLimits(t *testing.T) {
+	req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"a very long name"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Post("/users", HandleTo(testImportUser, WithJSONLimits(JSONLimits{MaxStringLength: 8})))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+type testOrderBatch struct {
+	Orders []testBatchLines  `json:"orders"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+type testBatchLines struct {
+	Lines []string `json:"lines"`
+}
+
+func TestMaxElements(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/orders", HandleTo(func(b testOrderBatch) (int, any, error) {
+		return http.StatusOK, nil, nil
+	}, WithJSONLimits(JSONLimits{MaxElements: 6})))
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{name: "Within limit", body: `{"orders":[{"lines":["a","b"]}],"tags":{"x":"1"}}`, expectedCode: http.StatusOK},
+		{name: "Counted across fields", body: `{"orders":[{"lines":["a","b"]},{"lines":["c"]}],"tags":{"x":"1","y":"2"}}`, expectedCode: http.StatusBadRequest},
+		{name: "Counted in nested slices", body: `{"orders":[{"lines":["a","b","c","d","e"]}]}`, expectedCode: http.StatusOK},
+		{name: "Nested slices over limit", body: `{"orders":[{"lines":["a","b","c","d","e","f"]}]}`, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orders", bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+
+	batch := testOrderBatch{Orders: []testBatchLines{{Lines: []string{"a", "b", "c"}}}}
+	var limitErr *JSONLimitError
+	if err := checkDecodedElements(reflect.ValueOf(batch), 3); !errors.As(err, &limitErr) || limitErr.Path != "orders[0].lines" {
+		t.Errorf("Expected limit error at orders[0].lines, got %v", err)
+	}
+}
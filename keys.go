@@ -0,0 +1,136 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// KeyNormalizer maps a JSON key to a canonical form. Keys of the request body
+// and json names of the struct fields with the same canonical form match.
+type KeyNormalizer func(key string) string
+
+// WithKeyNormalizer makes the route match body keys to struct fields through
+// normalize, e.g. bodyrest.TolerantKeys to accept both first_name and
+// firstName. A key spelled exactly as the field's json name wins over other
+// spellings; a body with two other spellings of the same field is rejected
+// with 400.
+func WithKeyNormalizer(normalize KeyNormalizer) Option {
+	return func(cfg *routeConfig) {
+		cfg.keyNormalizer = normalize
+	}
+}
+
+// TolerantKeys is a KeyNormalizer ignoring case and the separators of
+// snake_case and kebab-case, so user_id, userId and UserID all match.
+func TolerantKeys(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// normalizeKeys rewrites the object keys of data that match a field of t
+// under normalize to the field's json name.
+func normalizeKeys(data []byte, t reflect.Type, normalize KeyNormalizer) ([]byte, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	v, err := renameKeys(v, t, normalize)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// renameKeys renames the keys of v matching the fields of t. A key spelled
+// as the json name wins over other spellings; several other spellings of
+// the same field and no exact one are ambiguous and fail.
+func renameKeys(v any, t reflect.Type, normalize KeyNormalizer) (any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := map[string]reflect.StructField{}
+			collectKeyFields(t, normalize, fields)
+
+			out := make(map[string]any, len(value))
+			spellings := map[string]string{}
+			for key, item := range value {
+				field, ok := fields[normalize(key)]
+				if !ok {
+					out[key] = item
+					continue
+				}
+
+				name := jsonFieldName(field)
+				if key != name {
+					if _, exact := value[name]; exact {
+						continue
+					}
+					if other, seen := spellings[name]; seen {
+						return nil, fmt.Errorf("keys %q and %q both match field %q", min(key, other), max(key, other), name)
+					}
+					spellings[name] = key
+				}
+
+				renamed, err := renameKeys(item, field.Type, normalize)
+				if err != nil {
+					return nil, err
+				}
+				out[name] = renamed
+			}
+			return out, nil
+		case reflect.Map:
+			for key, item := range value {
+				renamed, err := renameKeys(item, t.Elem(), normalize)
+				if err != nil {
+					return nil, err
+				}
+				value[key] = renamed
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range value {
+				renamed, err := renameKeys(item, t.Elem(), normalize)
+				if err != nil {
+					return nil, err
+				}
+				value[i] = renamed
+			}
+		}
+	}
+
+	return v, nil
+}
+
+func collectKeyFields(t reflect.Type, normalize KeyNormalizer, fields map[string]reflect.StructField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			collectKeyFields(field.Type, normalize, fields)
+			continue
+		}
+
+		fields[normalize(jsonFieldName(field))] = field
+	}
+}
@@ -0,0 +1,74 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testContact struct {
+	FirstName string `json:"firstName"`
+	Address   struct {
+		PostalCode string `json:"postalCode"`
+	} `json:"address,omitempty"`
+	Tags []struct {
+		TagName string `json:"tagName"`
+	} `json:"tags,omitempty"`
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "snake case",
+			body:           `{"first_name":"John","address":{"postal_code":"12345"},"tags":[{"tag_name":"vip"}]}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"firstName":"John","address":{"postalCode":"12345"},"tags":[{"tagName":"vip"}]}`,
+		},
+		{
+			name:           "kebab and upper case",
+			body:           `{"FIRST-NAME":"John"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"firstName":"John","address":{"postalCode":""}}`,
+		},
+		{
+			name:           "exact key wins",
+			body:           `{"first_name":"Jane","firstName":"John"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"firstName":"John","address":{"postalCode":""}}`,
+		},
+		{
+			name:           "ambiguous keys",
+			body:           `{"first_name":"Jane","FirstName":"John"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/profiles", strings.NewReader(tc.body))
+
+			r := chi.NewRouter()
+			r.Post("/profiles", HandleTo(func(p testContact) (testContact, error) {
+				return p, nil
+			}, WithKeyNormalizer(TolerantKeys)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
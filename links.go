@@ -0,0 +1,108 @@
+package bodyrest
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Link is a hypermedia link of a response object.
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"`
+}
+
+// Links are the links of a response object by relation, e.g. "self".
+type Links map[string]Link
+
+// Linker is implemented by response types that link to related resources.
+// Auto-encoded responses get the links of each such object as a _links
+// member.
+type Linker interface {
+	Links() Links
+}
+
+var (
+	linkersMu    sync.RWMutex
+	linkBuilders = map[reflect.Type]func(v any) Links{}
+	linksCache   sync.Map
+	linkerType   = typeOf[Linker]()
+)
+
+// RegisterLinks adds links built by build to encoded values of type T, for
+// types that cannot implement Linker themselves.
+func RegisterLinks[T any](build func(v T) Links) {
+	linkersMu.Lock()
+	defer linkersMu.Unlock()
+
+	linkBuilders[typeOf[T]()] = func(v any) Links {
+		return build(v.(T))
+	}
+	linksCache.Clear()
+}
+
+// Href builds a link from a route pattern by replacing its {params}, in
+// order, with the escaped values of params, e.g. Href("/users/{id}", 1).
+// Regexp constraints of the params are dropped.
+func Href(pattern string, params ...any) string {
+	var b strings.Builder
+	last := 0
+	for i, p := range parsePlaceholders(pattern) {
+		if i == len(params) {
+			break
+		}
+
+		b.WriteString(pattern[last:p.start])
+		b.WriteString(url.PathEscape(fmt.Sprint(params[i])))
+		last = p.end
+	}
+	b.WriteString(pattern[last:])
+
+	return b.String()
+}
+
+// typeHasLinks reports whether values of t, or of types it contains, have
+// links.
+func typeHasLinks(t reflect.Type) bool {
+	if has, ok := linksCache.Load(t); ok {
+		return has.(bool)
+	}
+
+	has := searchType(t, func(t reflect.Type) bool {
+		return linkBuilderFor(t) != nil || t.Implements(linkerType) || reflect.PointerTo(t).Implements(linkerType)
+	}, map[reflect.Type]bool{})
+	linksCache.Store(t, has)
+	return has
+}
+
+func linkBuilderFor(t reflect.Type) func(v any) Links {
+	linkersMu.RLock()
+	defer linkersMu.RUnlock()
+
+	return linkBuilders[t]
+}
+
+// linksOf returns the links of the struct value v, if any.
+func linksOf(v reflect.Value) Links {
+	if !v.CanInterface() {
+		return nil
+	}
+
+	if build := linkBuilderFor(v.Type()); build != nil {
+		return build(v.Interface())
+	}
+
+	if linker, ok := v.Interface().(Linker); ok {
+		return linker.Links()
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(linkerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(Linker).Links()
+	}
+
+	return nil
+}
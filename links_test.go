@@ -0,0 +1,73 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testOrgMember struct {
+	Org string `json:"org"`
+	ID  int    `json:"id"`
+}
+
+func (m *testOrgMember) Links() Links {
+	return Links{
+		"self": {Href: Href("/orgs/{org}/users/{id:[0-9]+}", m.Org, m.ID)},
+		"org":  {Href: Href("/orgs/{org}", m.Org)},
+	}
+}
+
+type testInvoice struct {
+	Number string `json:"number"`
+}
+
+func TestLinks(t *testing.T) {
+	RegisterLinks(func(i testInvoice) Links {
+		return Links{"pay": {Href: Href("/invoices/{number}/payments", i.Number), Method: http.MethodPost}}
+	})
+
+	testCases := []struct {
+		name         string
+		handler      interface{}
+		expectedBody string
+	}{
+		{
+			name:         "linker",
+			handler:      func() (testOrgMember, error) { return testOrgMember{Org: "acme corp", ID: 1}, nil },
+			expectedBody: `{"org":"acmecorp","id":1,"_links":{"org":{"href":"/orgs/acme%20corp"},"self":{"href":"/orgs/acme%20corp/users/1"}}}`,
+		},
+		{
+			name:         "collection",
+			handler:      func() ([]*testOrgMember, error) { return []*testOrgMember{{Org: "acme", ID: 2}}, nil },
+			expectedBody: `[{"org":"acme","id":2,"_links":{"org":{"href":"/orgs/acme"},"self":{"href":"/orgs/acme/users/2"}}}]`,
+		},
+		{
+			name:         "registered builder",
+			handler:      func() (testInvoice, error) { return testInvoice{Number: "A-1"}, nil },
+			expectedBody: `{"number":"A-1","_links":{"pay":{"href":"/invoices/A-1/payments","method":"POST"}}}`,
+		},
+		{
+			name:         "no links",
+			handler:      func() (testUser, error) { return testUser{Name: "john"}, nil },
+			expectedBody: `{"name":"john"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			HandleTo(tc.handler).ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if strings.Join(strings.Fields(w.Body.String()), "") != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
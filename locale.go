@@ -0,0 +1,113 @@
+package bodyrest
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale can be taken as a handler parameter to receive the best supported
+// locale for the Accept-Language header of the request.
+type Locale string
+
+var supportedLocales = []Locale{"en"}
+
+// SetSupportedLocales sets the locales Locale parameters are matched
+// against. The first one is used when nothing in Accept-Language matches.
+func SetSupportedLocales(locales ...string) {
+	if len(locales) == 0 {
+		return
+	}
+
+	supportedLocales = make([]Locale, len(locales))
+	for i, locale := range locales {
+		supportedLocales[i] = Locale(locale)
+	}
+}
+
+// RequestLocale resolves the locale of r the same way Locale parameters are
+// resolved, e.g. for translating messages in the rest error handler.
+func RequestLocale(r *http.Request) Locale {
+	for _, tag := range acceptedLanguages(r.Header.Get("Accept-Language")) {
+		if locale, ok := matchLocale(tag); ok {
+			return locale
+		}
+	}
+
+	return supportedLocales[0]
+}
+
+// matchLocale matches a language tag exactly or, failing that, by its base
+// language, so "en-GB" matches "en" and "pt" matches "pt-BR".
+func matchLocale(tag string) (Locale, bool) {
+	if tag == "*" {
+		return supportedLocales[0], true
+	}
+
+	for _, locale := range supportedLocales {
+		if strings.EqualFold(string(locale), tag) {
+			return locale, true
+		}
+	}
+
+	base, _, _ := strings.Cut(tag, "-")
+	for _, locale := range supportedLocales {
+		localeBase, _, _ := strings.Cut(string(locale), "-")
+		if strings.EqualFold(localeBase, base) {
+			return locale, true
+		}
+	}
+
+	return "", false
+}
+
+// acceptedLanguages returns the tags of an Accept-Language header ordered by
+// preference, without the ones refused with q=0.
+func acceptedLanguages(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
+
+func init() {
+	registerInjector(typeOf[Locale](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(RequestLocale(r)), nil
+	})
+}
@@ -0,0 +1,36 @@
+package bodyrest
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestLocale(t *testing.T) {
+	SetSupportedLocales("en", "de", "pt-BR")
+	defer SetSupportedLocales("en")
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expectedLocale Locale
+	}{
+		{name: "No header", expectedLocale: "en"},
+		{name: "Exact match", acceptLanguage: "de", expectedLocale: "de"},
+		{name: "Region falls back to base", acceptLanguage: "de-AT", expectedLocale: "de"},
+		{name: "Base matches region", acceptLanguage: "pt", expectedLocale: "pt-BR"},
+		{name: "Preference by quality", acceptLanguage: "fr;q=0.9, de;q=0.5, pt-br;q=0.8", expectedLocale: "pt-BR"},
+		{name: "Refused language", acceptLanguage: "de;q=0, fr", expectedLocale: "en"},
+		{name: "Unsupported language", acceptLanguage: "ja", expectedLocale: "en"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			if locale := RequestLocale(req); locale != tc.expectedLocale {
+				t.Errorf("Expected locale %s, got %s", tc.expectedLocale, locale)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package bodyrest
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+var contextType = typeOf[context.Context]()
+
+// HandleLongPoll binds the request like HandleTo and then waits for fresh
+// data. The handler must take a context.Context as its last parameter and
+// return a receive channel and an error, e.g.
+// func(req T, ctx context.Context) (<-chan V, error). The first value
+// received is encoded with status 200. If timeout passes or the channel is
+// closed first, the response is 304 for conditional requests (If-None-Match
+// or If-Modified-Since) and 204 otherwise. ctx is canceled once the wait
+// ends, including when the client disconnects, so producers can unsubscribe.
+func HandleLongPoll(handlerFunc interface{}, timeout time.Duration, opts ...Option) http.HandlerFunc {
+	handlerType := reflect.TypeOf(handlerFunc)
+	if handlerType.Kind() != reflect.Func {
+		log.Fatal("Handler is not a function")
+	}
+
+	if handlerType.NumIn() == 0 || handlerType.In(handlerType.NumIn()-1) != contextType {
+		log.Fatal("long-poll handler must take context.Context as its last parameter")
+	}
+
+	if handlerType.NumOut() != 2 || handlerType.Out(0).Kind() != reflect.Chan ||
+		handlerType.Out(0).ChanDir()&reflect.RecvDir == 0 || handlerType.Out(1) != errorType {
+		log.Fatal("long-poll handler must return a receive channel and an error")
+	}
+
+	cfg := newRouteConfig(opts)
+	plan := newBindPlan(handlerType, handlerType.NumIn()-1)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer recoverHandler(w, r)
+
+		finish, ok := admitRequest(w, r)
+		if !ok {
+			return
+		}
+		defer finish()
+
+		handlerArgsToCall, ok := bindArgs(w, r, cfg, plan)
+		if !ok {
+			return
+		}
+		defer releaseArgs(handlerArgsToCall)
+
+		ctx, stop := withDrainSignal(r.Context())
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		handlerArgsToCall = append(handlerArgsToCall, reflect.ValueOf(ctx))
+		results := reflect.ValueOf(handlerFunc).Call(handlerArgsToCall)
+		if err, _ := results[1].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		chosen, value, received := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: results[0]},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		})
+
+		switch {
+		case chosen == 0 && received:
+			writeResponse(w, r, cfg, responseOf(http.StatusOK, value))
+		case r.Context().Err() != nil:
+			log.Printf("long-poll client went away: %v\n", r.Context().Err())
+		case r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "":
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
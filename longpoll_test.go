@@ -0,0 +1,96 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandleLongPoll(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	testCases := []struct {
+		name           string
+		url            string
+		header         map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "fresh data", url: "/users/john/events", expectedStatus: http.StatusOK, expectedBody: `{"name":"john"}`},
+		{name: "timeout", url: "/users/idle/events", expectedStatus: http.StatusNoContent},
+		{name: "conditional timeout", url: "/users/idle/events", header: map[string]string{"If-None-Match": `"v1"`}, expectedStatus: http.StatusNotModified},
+		{name: "closed channel", url: "/users/closed/events", expectedStatus: http.StatusNoContent},
+		{name: "handler error", url: "/users/exists/events", expectedStatus: http.StatusConflict},
+	}
+
+	canceled := make(chan struct{}, len(testCases))
+	r := chi.NewRouter()
+	r.Get("/users/{name}/events", HandleLongPoll(func(name string, ctx context.Context) (<-chan testUser, error) {
+		if name == "exists" {
+			return nil, errTestUserExists
+		}
+
+		events := make(chan testUser, 1)
+		switch name {
+		case "john":
+			events <- testUser{Name: name}
+		case "closed":
+			close(events)
+		}
+
+		go func() {
+			<-ctx.Done()
+			canceled <- struct{}{}
+		}()
+		return events, nil
+	}, 20*time.Millisecond))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			for key, value := range tc.header {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	for i := 0; i < len(testCases)-1; i++ {
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("Expected handler context to be canceled")
+		}
+	}
+}
+
+func TestHandleLongPollClientGone(t *testing.T) {
+	handler := HandleLongPoll(func(ctx context.Context) (<-chan testUser, error) {
+		return make(chan testUser), nil
+	}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("Expected no response for a disconnected client, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,71 @@
+package bodyrest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrMaintenance rejects requests while maintenance mode is on. It wraps
+// ErrServiceUnavailable and so is mapped to 503.
+var ErrMaintenance = fmt.Errorf("maintenance: %w", ErrServiceUnavailable)
+
+var maintenance struct {
+	mu         sync.RWMutex
+	enabled    bool
+	retryAfter time.Duration
+	allowlist  []string
+}
+
+// SetMaintenance turns maintenance mode on or off at runtime. While it is on,
+// every route wrapped by bodyrest answers with ErrMaintenance and a
+// Retry-After of retryAfter before any binding, except the routes of the
+// maintenance allowlist.
+func SetMaintenance(enabled bool, retryAfter time.Duration) {
+	maintenance.mu.Lock()
+	defer maintenance.mu.Unlock()
+
+	maintenance.enabled = enabled
+	maintenance.retryAfter = retryAfter
+}
+
+// InMaintenance reports whether maintenance mode is on, e.g. for readiness
+// checks.
+func InMaintenance() bool {
+	maintenance.mu.RLock()
+	defer maintenance.mu.RUnlock()
+
+	return maintenance.enabled
+}
+
+// SetMaintenanceAllowlist sets the routes served during maintenance, keyed
+// by method and route pattern such as "GET /health", or by pattern alone for
+// every method.
+func SetMaintenanceAllowlist(routes ...string) {
+	maintenance.mu.Lock()
+	defer maintenance.mu.Unlock()
+
+	maintenance.allowlist = slices.Clone(routes)
+}
+
+// rejectMaintenance writes ErrMaintenance and returns true when r must not
+// be served because of maintenance mode.
+func rejectMaintenance(w http.ResponseWriter, r *http.Request) bool {
+	maintenance.mu.RLock()
+	enabled, retryAfter, allowlist := maintenance.enabled, maintenance.retryAfter, maintenance.allowlist
+	maintenance.mu.RUnlock()
+
+	if !enabled {
+		return false
+	}
+
+	pattern := routePattern(r)
+	if slices.Contains(allowlist, pattern) || slices.Contains(allowlist, r.Method+" "+pattern) {
+		return false
+	}
+
+	writeError(w, r, statusFromError(ErrMaintenance), RetryAfter(ErrMaintenance, retryAfter))
+	return true
+}
@@ -0,0 +1,85 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSetMaintenance(t *testing.T) {
+	SetMaintenanceAllowlist("GET /health", "/admin/maintenance")
+	defer SetMaintenanceAllowlist()
+	defer SetMaintenance(false, 0)
+
+	r := chi.NewRouter()
+	r.Post("/users", HandleTo(func(u testUser) (testUser, error) { return u, nil }))
+	r.Get("/health", HandleTo(func() (int, any, error) { return http.StatusOK, nil, nil }))
+	r.Delete("/admin/maintenance", HandleTo(func() (int, any, error) {
+		SetMaintenance(false, 0)
+		return http.StatusNoContent, nil, nil
+	}))
+
+	testCases := []struct {
+		name               string
+		maintenance        bool
+		method             string
+		path               string
+		body               string
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{name: "off", method: "POST", path: "/users", body: `{"name":"john"}`, expectedStatus: http.StatusOK},
+		{name: "on", maintenance: true, method: "POST", path: "/users", body: `{"name":"john"}`, expectedStatus: http.StatusServiceUnavailable, expectedRetryAfter: "30"},
+		{name: "on before binding", maintenance: true, method: "POST", path: "/users", body: `{"name":`, expectedStatus: http.StatusServiceUnavailable, expectedRetryAfter: "30"},
+		{name: "allowlisted route", maintenance: true, method: "GET", path: "/health", expectedStatus: http.StatusOK},
+		{name: "allowlisted pattern", maintenance: true, method: "DELETE", path: "/admin/maintenance", expectedStatus: http.StatusNoContent},
+		{name: "turned off", method: "POST", path: "/users", body: `{"name":"john"}`, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.maintenance {
+				SetMaintenance(true, 30*time.Second)
+			}
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if got := w.Header().Get("Retry-After"); got != tc.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tc.expectedRetryAfter, got)
+			}
+		})
+	}
+
+	if InMaintenance() {
+		t.Error("Expected maintenance mode off")
+	}
+}
+
+func TestSetMaintenanceJSONRPC(t *testing.T) {
+	defer SetMaintenance(false, 0)
+
+	rpc := NewJSONRPC()
+	rpc.Register("user.import", testImportUser)
+
+	SetMaintenance(true, 30*time.Second)
+	req := httptest.NewRequest("POST", "/rpc", strings.NewReader(`{"jsonrpc":"2.0","method":"user.import","params":{"name":"john"},"id":1}`))
+	w := httptest.NewRecorder()
+	rpc.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Expected Retry-After %q, got %q", "30", got)
+	}
+}
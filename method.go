@@ -0,0 +1,64 @@
+package bodyrest
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Method can be taken as a handler parameter to receive the request method,
+// e.g. by handlers registered for several methods.
+type Method string
+
+func init() {
+	registerInjector(typeOf[Method](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(Method(r.Method)), nil
+	})
+}
+
+// WithMethodAwareBinding lets one handler serve reads and writes: for POST,
+// PUT and PATCH the struct parameter is decoded from the body as usual, for
+// other methods its body fields are bound from the query string by their
+// json names instead.
+func WithMethodAwareBinding() Option {
+	return func(cfg *routeConfig) {
+		cfg.methodAwareBinding = true
+	}
+}
+
+func isWriteMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// bindQueryFields fills the body fields of the struct v, those without a
+// source tag, from the query parameters named after their json names.
+func bindQueryFields(query map[string][]string, v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			if err := bindQueryFields(query, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, _, ok := sourceTagOf(field); ok {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		values := query[name]
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setFieldFromStrings(v.Field(i), values); err != nil {
+			return sourceFieldError(field, "query", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testSearch struct {
+	Term  string   `json:"term"`
+	Limit int      `json:"limit,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func testSearchUsers(method Method, s testSearch) (map[string]any, error) {
+	return map[string]any{"method": method, "term": s.Term, "limit": s.Limit, "tags": s.Tags}, nil
+}
+
+func TestMethodAwareBinding(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "query for GET",
+			method:       "GET",
+			target:       "/users/search?term=jo&limit=5&tags=a&tags=b",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"limit":5,"method":"GET","tags":["a","b"],"term":"jo"}`,
+		},
+		{
+			name:         "body for POST",
+			method:       "POST",
+			target:       "/users/search?term=ignored",
+			body:         `{"term":"jo","limit":5}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"limit":5,"method":"POST","tags":null,"term":"jo"}`,
+		},
+		{
+			name:         "required query field",
+			method:       "GET",
+			target:       "/users/search?limit=5",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid query value",
+			method:       "GET",
+			target:       "/users/search?term=jo&limit=five",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+
+			handler := HandleTo(testSearchUsers, WithMethodAwareBinding())
+			r := chi.NewRouter()
+			r.Get("/users/search", handler)
+			r.Post("/users/search", handler)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package bodyrest
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Mount registers on rt the routes declared by the struct tags of ctrl, a
+// struct or a pointer to one. Func fields are registered as handlers of
+// their route tag, and blank fields name a method of ctrl with a handler
+// tag, since methods cannot carry tags:
+//
+//	type UserController struct {
+//		_      struct{} `route:"POST /users" handler:"Create"`
+//		_      struct{} `route:"GET /users/{id}" handler:"Get" name:"user"`
+//		Delete func(id int) (int, any, error) `route:"DELETE /users/{id}"`
+//	}
+//
+//	func (c *UserController) Create(u User) (int, any, error) { ... }
+//
+// A name tag names the route for URLFor. opts apply to every route. Mount
+// fails like Router.Handle on invalid tags and handlers.
+func Mount(rt *Router, ctrl any, opts ...Option) {
+	routes, err := controllerRoutes(ctrl)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, route := range routes {
+		routeOpts := opts
+		if route.name != "" {
+			routeOpts = append(slices.Clip(opts), WithRouteName(route.name))
+		}
+		rt.Handle(route.method, route.pattern, route.handler, routeOpts...)
+	}
+}
+
+// controllerRoute is a route declared by a field of a controller.
+type controllerRoute struct {
+	method  string
+	pattern string
+	name    string
+	handler any
+}
+
+func controllerRoutes(ctrl any) ([]controllerRoute, error) {
+	value := reflect.ValueOf(ctrl)
+	structValue := reflect.Indirect(value)
+	if structValue.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("controller %T is not a struct", ctrl)
+	}
+
+	var routes []controllerRoute
+	t := structValue.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("route")
+		if !ok {
+			continue
+		}
+
+		method, pattern, ok := strings.Cut(strings.TrimSpace(tag), " ")
+		method = strings.ToUpper(method)
+		if !ok || !slices.Contains(routingMethods, method) || !strings.HasPrefix(strings.TrimSpace(pattern), "/") {
+			return nil, fmt.Errorf("controller %s: field %s: route tag %q is not \"METHOD /pattern\"", t, field.Name, tag)
+		}
+
+		route := controllerRoute{method: method, pattern: strings.TrimSpace(pattern), name: field.Tag.Get("name")}
+		switch name := field.Tag.Get("handler"); {
+		case name != "":
+			handler := value.MethodByName(name)
+			if !handler.IsValid() {
+				return nil, fmt.Errorf("controller %s: field %s: %T has no method %s", t, field.Name, ctrl, name)
+			}
+			route.handler = handler.Interface()
+		case field.Type.Kind() == reflect.Func && field.IsExported():
+			handler := structValue.Field(i)
+			if handler.IsNil() {
+				return nil, fmt.Errorf("controller %s: field %s: handler is nil", t, field.Name)
+			}
+			route.handler = handler.Interface()
+		default:
+			return nil, fmt.Errorf("controller %s: field %s: route tag on a field that is neither an exported func nor names a method with a handler tag", t, field.Name)
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
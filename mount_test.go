@@ -0,0 +1,97 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testUserController struct {
+	_      struct{}                       `route:"POST /ctrl/users" handler:"Create"`
+	_      struct{}                       `route:"GET /ctrl/users/{id}" handler:"Get" name:"ctrl.user.get"`
+	Delete func(id int) (int, any, error) `route:"DELETE /ctrl/users/{id}"`
+
+	users map[int]testUser
+}
+
+func (c *testUserController) Create(u testUser) (int, any, error) {
+	c.users[len(c.users)+1] = u
+	return http.StatusCreated, u, nil
+}
+
+func (c *testUserController) Get(id int) (int, any, error) {
+	u, ok := c.users[id]
+	if !ok {
+		return http.StatusNotFound, nil, nil
+	}
+	return http.StatusOK, u, nil
+}
+
+func TestMount(t *testing.T) {
+	ctrl := &testUserController{users: map[int]testUser{}}
+	ctrl.Delete = func(id int) (int, any, error) {
+		delete(ctrl.users, id)
+		return http.StatusNoContent, nil, nil
+	}
+
+	rt := NewRouter(nil)
+	Mount(rt, ctrl)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{name: "Method handler", method: "POST", path: "/ctrl/users", body: `{"name":"John"}`, expectedCode: http.StatusCreated},
+		{name: "Method handler with path param", method: "GET", path: "/ctrl/users/1", expectedCode: http.StatusOK},
+		{name: "Func field handler", method: "DELETE", path: "/ctrl/users/1", expectedCode: http.StatusNoContent},
+		{name: "Deleted", method: "GET", path: "/ctrl/users/1", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+
+	if url, err := URLFor("ctrl.user.get", 7); err != nil || url != "/ctrl/users/7" {
+		t.Errorf("Expected named route /ctrl/users/7, got %q, %v", url, err)
+	}
+}
+
+func TestControllerRoutesErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		ctrl any
+	}{
+		{name: "Not a struct", ctrl: 42},
+		{name: "Invalid route tag", ctrl: &struct {
+			List func() error `route:"/users"`
+		}{List: func() error { return nil }}},
+		{name: "Unknown method", ctrl: &struct {
+			_ struct{} `route:"GET /users" handler:"List"`
+		}{}},
+		{name: "Nil func field", ctrl: &struct {
+			List func() error `route:"GET /users"`
+		}{}},
+		{name: "Field neither func nor method", ctrl: &struct {
+			List string `route:"GET /users"`
+		}{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := controllerRoutes(tc.ctrl); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package bodyrest
+
+import (
+	"log"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// removeMultipartFiles controls whether temporary files of parsed multipart
+// forms are removed once the handler returns.
+var removeMultipartFiles = true
+
+// SetMultipartCleanup sets whether bodyrest removes the temporary files of
+// multipart forms, parsed for multipart.Form and LazyForm parameters, when
+// the handler returns. It is enabled by default; disable it only if handlers
+// keep using uploaded files after returning.
+func SetMultipartCleanup(enabled bool) {
+	removeMultipartFiles = enabled
+}
+
+// maxMultipartMemory is the part of a multipart body kept in memory, the
+// rest is stored in temporary files.
+const maxMultipartMemory = 32 << 20
+
+func init() {
+	registerInjector(typeOf[*LazyForm](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(&LazyForm{r: r}), nil
+	})
+}
+
+// LazyForm gives a handler access to a multipart form that is only parsed
+// when first used, unlike a multipart.Form parameter. It is safe for
+// concurrent use and its temporary files are removed when the handler
+// returns.
+type LazyForm struct {
+	r *http.Request
+
+	once sync.Once
+	form *multipart.Form
+	err  error
+}
+
+// Form parses the multipart body on first call and returns it.
+func (f *LazyForm) Form() (*multipart.Form, error) {
+	f.once.Do(func() {
+		if err := f.r.ParseMultipartForm(maxMultipartMemory); err != nil {
+			f.err = err
+			return
+		}
+		f.form = f.r.MultipartForm
+	})
+
+	return f.form, f.err
+}
+
+// Value returns the first value of the form field key.
+func (f *LazyForm) Value(key string) (string, error) {
+	form, err := f.Form()
+	if err != nil {
+		return "", err
+	}
+
+	if values := form.Value[key]; len(values) > 0 {
+		return values[0], nil
+	}
+
+	return "", nil
+}
+
+// File opens the first file uploaded for the form field key.
+func (f *LazyForm) File(key string) (multipart.File, *multipart.FileHeader, error) {
+	form, err := f.Form()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if files := form.File[key]; len(files) > 0 {
+		file, err := files[0].Open()
+		return file, files[0], err
+	}
+
+	return nil, nil, http.ErrMissingFile
+}
+
+func (f *LazyForm) release() {
+	f.once.Do(func() {})
+	if f.form != nil {
+		removeFormFiles(f.form)
+	}
+}
+
+func removeFormFiles(form *multipart.Form) {
+	if !removeMultipartFiles {
+		return
+	}
+
+	if err := form.RemoveAll(); err != nil {
+		log.Printf("failed to remove multipart files: %v\n", err)
+	}
+}
+
+// releaser is implemented by bound parameters holding resources that must be
+// freed once the handler has returned.
+type releaser interface {
+	release()
+}
+
+func releaseArgs(args []reflect.Value) {
+	for _, arg := range args {
+		if !arg.IsValid() || !arg.CanInterface() {
+			continue
+		}
+
+		switch v := arg.Interface().(type) {
+		case releaser:
+			v.release()
+		case multipart.Form:
+			removeFormFiles(&v)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package bodyrest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestMultipartRequest(t *testing.T) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("title", "report"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := mw.CreateFormFile("file", "report.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("quarterly numbers"))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/uploads", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestLazyForm(t *testing.T) {
+	testCases := []struct {
+		name         string
+		handler      interface{}
+		request      func(t *testing.T) *http.Request
+		expectedBody string
+	}{
+		{
+			name:    "reads value and file",
+			request: newTestMultipartRequest,
+			handler: func(form *LazyForm) (map[string]string, error) {
+				title, err := form.Value("title")
+				if err != nil {
+					return nil, err
+				}
+
+				file, header, err := form.File("file")
+				if err != nil {
+					return nil, err
+				}
+				defer file.Close()
+
+				content, err := io.ReadAll(file)
+				if err != nil {
+					return nil, err
+				}
+
+				return map[string]string{"title": title, header.Filename: string(content)}, nil
+			},
+			expectedBody: `{"report.txt":"quarterly numbers","title":"report"}` + "\n",
+		},
+		{
+			name: "malformed form not used",
+			request: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest("POST", "/uploads", bytes.NewBufferString("not a form"))
+				req.Header.Set("Content-Type", "multipart/form-data; boundary=missing")
+				return req
+			},
+			handler: func(form *LazyForm) (map[string]string, error) {
+				return map[string]string{"parsed": "no"}, nil
+			},
+			expectedBody: `{"parsed":"no"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.request(t)
+
+			r := chi.NewRouter()
+			r.Post("/uploads", HandleTo(tc.handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+
+		})
+	}
+}
+
+func TestLazyFormMissingFile(t *testing.T) {
+	form := &LazyForm{r: newTestMultipartRequest(t)}
+
+	if _, _, err := form.File("avatar"); err != http.ErrMissingFile {
+		t.Errorf("Expected http.ErrMissingFile, got %v", err)
+	}
+
+	form.release()
+}
+
+func TestMultipartCleanup(t *testing.T) {
+	testCases := []struct {
+		name          string
+		enabled       bool
+		expectedFiles int
+	}{
+		{
+			name:          "files removed",
+			enabled:       true,
+			expectedFiles: 0,
+		},
+		{
+			name:          "cleanup disabled",
+			enabled:       false,
+			expectedFiles: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetMultipartCleanup(tc.enabled)
+			defer SetMultipartCleanup(true)
+
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			req := newTestMultipartRequest(t)
+			_, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			form, err := multipart.NewReader(req.Body, params["boundary"]).ReadForm(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			releaseArgs([]reflect.Value{reflect.ValueOf(*form)})
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(entries) != tc.expectedFiles {
+				t.Errorf("Expected %d temporary files, got %d", tc.expectedFiles, len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable holds a request field that can be absent, explicitly null or set,
+// so PATCH handlers can tell "clear this field" from "leave it unchanged".
+// Nullable fields are not required unless tagged required:"true", which
+// then only demands that the field is present.
+type Nullable[T any] struct {
+	Value   T
+	Present bool
+	Null    bool
+}
+
+// NullableOf returns a Nullable set to value.
+func NullableOf[T any](value T) Nullable[T] {
+	return Nullable[T]{Value: value, Present: true}
+}
+
+// IsSet reports whether the field was sent with a non-null value.
+func (n Nullable[T]) IsSet() bool {
+	return n.Present && !n.Null
+}
+
+// Get returns the value and whether it is set.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.IsSet()
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Present = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		n.Value = zero
+		n.Null = true
+		return nil
+	}
+
+	n.Null = false
+	return json.Unmarshal(data, &n.Value)
+}
+
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.IsSet() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.Value)
+}
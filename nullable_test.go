@@ -0,0 +1,100 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testPatchUser struct {
+	Name     Nullable[string] `json:"name"`
+	Nickname Nullable[string] `json:"nickname"`
+	Age      Nullable[int]    `json:"age" required:"true"`
+}
+
+func TestNullable(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "set, null and absent",
+			body:         `{"name":"John","nickname":null,"age":30}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"age":"set 30","name":"set John","nickname":"null"}`,
+		},
+		{
+			name:         "absent",
+			body:         `{"age":null}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"age":"null","name":"absent","nickname":"absent"}`,
+		},
+		{
+			name:         "required field absent",
+			body:         `{"name":"John"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	describe := func(present, null bool, value any) string {
+		switch {
+		case !present:
+			return "absent"
+		case null:
+			return "null"
+		default:
+			return "set " + jsonString(value)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/users/1", strings.NewReader(tc.body))
+
+			r := chi.NewRouter()
+			r.Patch("/users/1", HandleTo(func(u testPatchUser) (map[string]string, error) {
+				return map[string]string{
+					"name":     describe(u.Name.Present, u.Name.Null, u.Name.Value),
+					"nickname": describe(u.Nickname.Present, u.Nickname.Null, u.Nickname.Value),
+					"age":      describe(u.Age.Present, u.Age.Null, u.Age.Value),
+				}, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNullableMarshal(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		A Nullable[int] `json:"a"`
+		B Nullable[int] `json:"b"`
+	}{A: NullableOf(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"a":1,"b":null}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+}
+
+func jsonString(v any) string {
+	encoded, _ := json.Marshal(v)
+	return strings.Trim(string(encoded), `"`)
+}
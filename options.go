@@ -0,0 +1,55 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Option configures a single route wrapped by HandleTo.
+type Option func(*routeConfig)
+
+type routeConfig struct {
+	maskableFields  []string
+	bulkConcurrency int
+	gates           []routeGate
+	defaultVersion  string
+	examples        []Example
+	wrappers        []func(next Invoker) Invoker
+	authorizers     []AuthorizeFunc
+	cacheControl    string
+	circuitBreaker  CircuitBreaker
+	responseCache   *responseCache
+	responseType    reflect.Type
+	view            string
+	selectableViews []string
+	pathParams      []string
+	routeName       string
+	featureFlags    []routeFlag
+	disabledStatus  int
+	logRate         *float64
+	slowThreshold   time.Duration
+
+	rejectDuplicateKeys bool
+	jsonLimits          *JSONLimits
+	keyNormalizer       KeyNormalizer
+	decoderOptions      []func(dec *json.Decoder)
+	methodAwareBinding  bool
+	positionalParams    bool
+
+	signatureVerifier SignatureVerifierFunc
+	checksums         []bodyChecksum
+	bodyTransforms    []BodyTransformFunc
+	bindTimeout       time.Duration
+	handlerTimeout    time.Duration
+	coalescing        bool
+}
+
+func newRouteConfig(opts []Option) *routeConfig {
+	cfg := &routeConfig{bulkConcurrency: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
@@ -0,0 +1,66 @@
+package bodyrest
+
+import "errors"
+
+var (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// SetPageLimits sets the limit used when a PageRequest has none and the
+// maximum limit a client may ask for.
+func SetPageLimits(defaultLimit, maxLimit int) {
+	defaultPageLimit = defaultLimit
+	maxPageLimit = maxLimit
+}
+
+// PageRequest holds the pagination parameters of a list endpoint, bound from
+// the limit, offset and cursor query parameters. It can be used as a handler
+// parameter or embedded into a query struct. Limit is capped by the limits
+// set with SetPageLimits.
+type PageRequest struct {
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+	Cursor string `query:"cursor"`
+}
+
+func (p *PageRequest) afterBind() error {
+	if p.Limit < 0 || p.Offset < 0 {
+		return errors.New("page limit and offset must not be negative")
+	}
+
+	if p.Limit == 0 {
+		p.Limit = defaultPageLimit
+	}
+
+	if p.Limit > maxPageLimit {
+		p.Limit = maxPageLimit
+	}
+
+	return nil
+}
+
+// PageResponse is the envelope for a page of items returned by a list
+// endpoint.
+type PageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// NewPage builds the PageResponse for items returned for page, out of total
+// items overall.
+func NewPage[T any](page PageRequest, items []T, total int) PageResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	return PageResponse[T]{
+		Items:  items,
+		Total:  total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+}
@@ -0,0 +1,85 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testListParams struct {
+	PageRequest
+	Name string `query:"name"`
+}
+
+func testListUsers(p testListParams) (int, any, error) {
+	users := []testUser{{Name: p.Name}}
+	return http.StatusOK, NewPage(p.PageRequest, users, 42), nil
+}
+
+func testListAll(p PageRequest) (int, any, error) {
+	return http.StatusOK, NewPage[testUser](p, nil, 0), nil
+}
+
+func TestPagination(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		handler        interface{}
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Default limit",
+			path:           "/users?name=john",
+			handler:        testListUsers,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[{"name":"john"}],"total":42,"limit":20,"offset":0}`,
+		},
+		{
+			name:           "Limit above maximum is capped",
+			path:           "/users?name=john&limit=500&offset=40",
+			handler:        testListUsers,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[{"name":"john"}],"total":42,"limit":100,"offset":40}`,
+		},
+		{
+			name:           "Negative offset",
+			path:           "/users?offset=-1",
+			handler:        testListUsers,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+		{
+			name:           "Page request as parameter with no items",
+			path:           "/users?limit=5",
+			handler:        testListAll,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[],"total":0,"limit":5,"offset":0}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/users", HandleTo(tc.handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
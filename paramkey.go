@@ -0,0 +1,153 @@
+package bodyrest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// writeParamsKey writes the values of the params of plan bound or injected
+// from the request to buf, for keying calls that may share a response.
+// Context and provided params do not depend on the request and are left out.
+func writeParamsKey(buf *bytes.Buffer, plan *bindPlan, args []reflect.Value) error {
+	for i, param := range plan.paramPlans() {
+		if param.kind == paramProvided || param.typ == contextType || i >= len(args) {
+			continue
+		}
+
+		if err := writeValueKey(buf, args[i], 0); err != nil {
+			return fmt.Errorf("param %d: %w", i, err)
+		}
+		buf.WriteByte('\n')
+	}
+
+	return nil
+}
+
+// maxKeyDepth bounds the nesting walked by writeValueKey, against cyclic
+// values.
+const maxKeyDepth = 32
+
+// writeValueKey writes a representation of v to buf that is equal for equal
+// values: every struct field, exported or not and whatever its json tag, map
+// entries in key order and pointers by the value they point to. Funcs and
+// channels cannot be compared by value and fail.
+func writeValueKey(buf *bytes.Buffer, v reflect.Value, depth int) error {
+	if depth > maxKeyDepth {
+		return fmt.Errorf("value nested deeper than %d", maxKeyDepth)
+	}
+
+	if !v.IsValid() {
+		buf.WriteString("nil")
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		buf.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, 128))
+	case reflect.String:
+		buf.WriteString(strconv.Quote(v.String()))
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("nil")
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			buf.WriteString(v.Elem().Type().String())
+		}
+		buf.WriteByte('&')
+		return writeValueKey(buf, v.Elem(), depth+1)
+	case reflect.Struct:
+		buf.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			buf.WriteString(v.Type().Field(i).Name)
+			buf.WriteByte(':')
+			if err := writeValueKey(buf, v.Field(i), depth+1); err != nil {
+				return err
+			}
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("nil")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if err := writeValueKey(buf, v.Index(i), depth+1); err != nil {
+				return err
+			}
+			buf.WriteByte(',')
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("nil")
+			return nil
+		}
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var entry bytes.Buffer
+			if err := writeValueKey(&entry, iter.Key(), depth+1); err != nil {
+				return err
+			}
+			entry.WriteByte(':')
+			if err := writeValueKey(&entry, iter.Value(), depth+1); err != nil {
+				return err
+			}
+			entries = append(entries, entry.String())
+		}
+		slices.Sort(entries)
+		buf.WriteByte('{')
+		for _, entry := range entries {
+			buf.WriteString(entry)
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("%s values cannot be keyed", v.Type())
+	}
+
+	return nil
+}
+
+// checkKeyableParams reports the first param of handlerType whose values
+// writeParamsKey cannot key, such as *LazyForm, which holds the request.
+func checkKeyableParams(handlerType reflect.Type) error {
+	for i := 0; i < handlerType.NumIn(); i++ {
+		t := handlerType.In(i)
+		if t == contextType {
+			continue
+		}
+		if _, provided := providerFor(t); provided {
+			continue
+		}
+
+		if searchType(t, isUnkeyableType, map[reflect.Type]bool{}) {
+			return fmt.Errorf("handler %s takes %s, whose values cannot be compared between requests", handlerType, t)
+		}
+	}
+
+	return nil
+}
+
+func isUnkeyableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+
+	return false
+}
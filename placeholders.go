@@ -0,0 +1,202 @@
+package bodyrest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// placeholder is a {name} or {name:regexp} of a chi pattern, found at
+// pattern[start:end].
+type placeholder struct {
+	name       string
+	constraint string
+	start, end int
+}
+
+// parsePlaceholders returns the placeholders of a chi pattern in order. The
+// braces of regexp constraints, as in {date:\d{4}-\d{2}}, are matched so they
+// do not end the placeholder early.
+func parsePlaceholders(pattern string) []placeholder {
+	var placeholders []placeholder
+	depth, start := 0, 0
+	for i, c := range pattern {
+		switch c {
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				name, constraint, _ := strings.Cut(pattern[start+1:i], ":")
+				placeholders = append(placeholders, placeholder{
+					name:       strings.TrimSpace(name),
+					constraint: constraint,
+					start:      start,
+					end:        i + 1,
+				})
+			}
+		}
+	}
+
+	return placeholders
+}
+
+// routePlaceholders returns the names of the {placeholders} of a chi pattern
+// in order, without their regexp constraints.
+func routePlaceholders(pattern string) []string {
+	var names []string
+	for _, p := range parsePlaceholders(pattern) {
+		names = append(names, p.name)
+	}
+
+	return names
+}
+
+// pathFieldNames returns the route params bound by the path tags of the
+// fields of t, including embedded structs.
+func pathFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			names = append(names, pathFieldNames(field.Type)...)
+			continue
+		}
+
+		for _, src := range fieldSources(field) {
+			if src.source == "path" {
+				names = append(names, src.name)
+			}
+		}
+	}
+
+	return names
+}
+
+// checkPathParams verifies that every placeholder of pattern is bound by a
+// handler with path-tagged struct fields. Positional params take the first
+// placeholders in order, the remaining ones need a field each. Handlers
+// without path-tagged fields are not checked, as their positional params
+// may also take params of mounted routers.
+func checkPathParams(pattern string, handlerType reflect.Type) error {
+	if handlerType == nil || handlerType.Kind() != reflect.Func {
+		return nil
+	}
+
+	var fields []string
+	positional := 0
+	for _, param := range newBindPlan(handlerType, handlerType.NumIn()).paramPlans() {
+		switch param.kind {
+		case paramStruct:
+			fields = append(fields, pathFieldNames(param.typ)...)
+		case paramPath:
+			positional++
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	for i, name := range routePlaceholders(pattern) {
+		if i < positional || slices.Contains(fields, name) {
+			continue
+		}
+
+		return fmt.Errorf("route %s: placeholder {%s} has no path-tagged field in %s", pattern, name, handlerType)
+	}
+
+	return nil
+}
+
+// WithPathParams binds the path params of the handler by name instead of by
+// position: the n-th path param takes the route param named names[n]. Use it
+// when the handler declares its params in another order than the pattern.
+func WithPathParams(names ...string) Option {
+	return func(cfg *routeConfig) {
+		cfg.pathParams = names
+	}
+}
+
+// WithPositionalParams confirms that the path params of the handler are bound
+// by position, the default, for routes registered through a Router that
+// would otherwise be rejected as ambiguous.
+func WithPositionalParams() Option {
+	return func(cfg *routeConfig) {
+		cfg.positionalParams = true
+	}
+}
+
+// boundPathParam returns the n-th path param of the handler, by name when
+// the route names its path params and by position otherwise.
+func boundPathParam(r *http.Request, cfg *routeConfig, n int) (string, bool, error) {
+	if n >= len(cfg.pathParams) {
+		return routePathParam(r, n)
+	}
+
+	if chi.RouteContext(r.Context()) == nil && r.Pattern == "" {
+		return "", false, errNoRouteContext
+	}
+
+	value, err := urlParam(r, cfg.pathParams[n])
+	return value, value != "" && err == nil, err
+}
+
+// checkPathBinding verifies how the path params of a handler are bound.
+// Named params must each match a placeholder, one per path param. Binding
+// by position is ambiguous when several path params share a type and the
+// pattern has several placeholders, since swapping them in the signature
+// still binds; such routes must name their params or confirm the order
+// with WithPositionalParams.
+func checkPathBinding(pattern string, handlerType reflect.Type, cfg *routeConfig) error {
+	if handlerType == nil || handlerType.Kind() != reflect.Func {
+		return nil
+	}
+
+	var types []reflect.Type
+	for _, param := range newBindPlan(handlerType, handlerType.NumIn()).paramPlans() {
+		if param.kind == paramPath {
+			types = append(types, param.typ)
+		}
+	}
+	placeholders := routePlaceholders(pattern)
+
+	if len(cfg.pathParams) > 0 {
+		if len(cfg.pathParams) != len(types) {
+			return fmt.Errorf("route %s: %d path params named for %d path params of %s", pattern, len(cfg.pathParams), len(types), handlerType)
+		}
+
+		for _, name := range cfg.pathParams {
+			if !slices.Contains(placeholders, name) {
+				return fmt.Errorf("route %s: named path param {%s} is not a placeholder", pattern, name)
+			}
+		}
+
+		return nil
+	}
+
+	if cfg.positionalParams || len(placeholders) < 2 {
+		return nil
+	}
+
+	for i, typ := range types {
+		if slices.Contains(types[:i], typ) {
+			return fmt.Errorf("route %s: several path params of type %s bound by position in %s, name them with WithPathParams or confirm with WithPositionalParams", pattern, typ, handlerType)
+		}
+	}
+
+	return nil
+}
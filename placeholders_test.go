@@ -0,0 +1,235 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testIssueRef struct {
+	Org  string `path:"org"`
+	Repo string `path:"repo"`
+	Num  int    `path:"num"`
+}
+
+func testGetIssue(ref testIssueRef) (testIssueRef, error) {
+	return ref, nil
+}
+
+func TestPathParamsStruct(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Get("/orgs/{org}/repos/{repo}/issues/{num:[0-9]+}", testGetIssue)
+
+	req := httptest.NewRequest("GET", "/orgs/acme/repos/api/issues/42", nil)
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	expected := `{"Org":"acme","Repo":"api","Num":42}` + "\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestCheckPathParams(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pattern     string
+		handler     any
+		expectError bool
+	}{
+		{
+			name:    "Every placeholder has a field",
+			pattern: "/orgs/{org}/repos/{repo}/issues/{num}",
+			handler: testGetIssue,
+		},
+		{
+			name:        "Placeholder without field",
+			pattern:     "/orgs/{org}/repos/{repo}/issues/{id}",
+			handler:     testGetIssue,
+			expectError: true,
+		},
+		{
+			name:    "Positional params take the first placeholders",
+			pattern: "/tenants/{tenant}/orgs/{org}/repos/{repo}/issues/{num}",
+			handler: func(tenant string, ref testIssueRef) (testIssueRef, error) { return ref, nil },
+		},
+		{
+			name:    "Positional params only are not checked",
+			pattern: "/orgs/{org}/users/{id}",
+			handler: func(id int) (int, error) { return id, nil },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPathParams(tc.pattern, reflect.TypeOf(tc.handler))
+			if tc.expectError && err == nil {
+				t.Error("Expected an error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRoutePlaceholders(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "/files/{id:[0-9]{3}}/{name}/*", expected: []string{"id", "name"}},
+		{pattern: `/reports/{date:\d{4}-\d{2}-\d{2}}/{kind:[a-z/]+}`, expected: []string{"date", "kind"}},
+		{pattern: "/users", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			got := routePlaceholders(tc.pattern)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestConstrainedPatterns(t *testing.T) {
+	pattern := `/reports/{date:\d{4}-\d{2}-\d{2}}/items/{id:[0-9]+}`
+
+	href := Href(pattern, "2024-05-06", 7)
+	if href != "/reports/2024-05-06/items/7" {
+		t.Errorf("Expected href %q, got %q", "/reports/2024-05-06/items/7", href)
+	}
+
+	route := RouteInfo{
+		Method:      http.MethodGet,
+		Pattern:     pattern,
+		HandlerType: reflect.TypeOf(func(date string, id int) (int, error) { return id, nil }),
+	}
+	if got := samplePath(route); got != "/reports/2024-01-02/items/1" {
+		t.Errorf("Expected sample path %q, got %q", "/reports/2024-01-02/items/1", got)
+	}
+}
+
+func TestWithPathParams(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		handler        any
+		opts           []Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Binds by position",
+			path: "/orgs/1/users/2",
+			handler: func(org, user int) (map[string]int, error) {
+				return map[string]int{"org": org, "user": user}, nil
+			},
+			opts:           []Option{WithPositionalParams()},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"org":1,"user":2}` + "\n",
+		},
+		{
+			name: "Binds by name",
+			path: "/orgs/1/users/2",
+			handler: func(user, org int) (map[string]int, error) {
+				return map[string]int{"org": org, "user": user}, nil
+			},
+			opts:           []Option{WithPathParams("id", "org")},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"org":1,"user":2}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRouter(nil)
+			rt.Get("/orgs/{org}/users/{id}", tc.handler, tc.opts...)
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCheckPathBinding(t *testing.T) {
+	twoInts := reflect.TypeOf(func(org, id int) (int, error) { return id, nil })
+
+	testCases := []struct {
+		name        string
+		pattern     string
+		handlerType reflect.Type
+		opts        []Option
+		expectError bool
+	}{
+		{
+			name:        "Same-typed params by position",
+			pattern:     "/orgs/{org}/users/{id}",
+			handlerType: twoInts,
+			expectError: true,
+		},
+		{
+			name:        "Confirmed positional params",
+			pattern:     "/orgs/{org}/users/{id}",
+			handlerType: twoInts,
+			opts:        []Option{WithPositionalParams()},
+		},
+		{
+			name:        "Named params",
+			pattern:     "/orgs/{org}/users/{id}",
+			handlerType: twoInts,
+			opts:        []Option{WithPathParams("org", "id")},
+		},
+		{
+			name:        "Named param not in pattern",
+			pattern:     "/orgs/{org}/users/{id}",
+			handlerType: twoInts,
+			opts:        []Option{WithPathParams("org", "user")},
+			expectError: true,
+		},
+		{
+			name:        "Named param count mismatch",
+			pattern:     "/orgs/{org}/users/{id}",
+			handlerType: twoInts,
+			opts:        []Option{WithPathParams("id")},
+			expectError: true,
+		},
+		{
+			name:        "Distinct types",
+			pattern:     "/orgs/{org}/users/{id}",
+			handlerType: reflect.TypeOf(testGetOrgUser),
+		},
+		{
+			name:        "Single placeholder",
+			pattern:     "/users/{id}",
+			handlerType: twoInts,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPathBinding(tc.pattern, tc.handlerType, newRouteConfig(tc.opts))
+			if tc.expectError && err == nil {
+				t.Error("Expected an error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
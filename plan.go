@@ -0,0 +1,93 @@
+package bodyrest
+
+import (
+	"mime/multipart"
+	"reflect"
+	"sync"
+)
+
+type paramKind int
+
+const (
+	paramInjected paramKind = iota
+	paramMultipart
+	paramStruct
+	paramExtraStruct
+	paramPath
+	paramProvided
+)
+
+// paramPlan says where the value of one handler parameter comes from.
+type paramPlan struct {
+	typ    reflect.Type
+	kind   paramKind
+	inject injectorFunc
+	// provider creates provided params once the request is bound.
+	provider providerEntry
+	// hasBody is set for struct params with fields decoded from the body.
+	hasBody bool
+	// pathIndex is the position of the route param bound to a path param.
+	pathIndex int
+}
+
+// bindPlan caches how the parameters of a handler are bound, so bindArgs
+// does not inspect the handler type on every request. It is built on the
+// first request, once injectors registered at startup are known.
+type bindPlan struct {
+	handlerType reflect.Type
+	n           int
+	// provides is set when the caller begins provided params, as HandleTo
+	// does through its invoker.
+	provides bool
+
+	once   sync.Once
+	params []paramPlan
+}
+
+func newBindPlan(handlerType reflect.Type, n int) *bindPlan {
+	return &bindPlan{handlerType: handlerType, n: n}
+}
+
+var multipartFormType = reflect.TypeOf(multipart.Form{})
+
+func (p *bindPlan) paramPlans() []paramPlan {
+	p.once.Do(func() {
+		p.params = make([]paramPlan, p.n)
+		pathIndex := 0
+		hasBodyStruct := false
+		for i := range p.params {
+			param := paramPlan{typ: p.handlerType.In(i)}
+
+			inject, injected := injectorFor(param.typ)
+			provider, provided := providerFor(param.typ)
+			switch {
+			case injected:
+				param.kind = paramInjected
+				param.inject = inject
+			case provided:
+				param.kind = paramProvided
+				param.provider = provider
+			case param.typ == multipartFormType && !hasBodyStruct:
+				param.kind = paramMultipart
+				hasBodyStruct = true
+			case param.typ.Kind() == reflect.Struct && !hasBodyFields(param.typ):
+				// bound from source tags only, alongside the body struct
+				param.kind = paramStruct
+			case param.typ.Kind() == reflect.Struct && hasBodyStruct:
+				param.kind = paramExtraStruct
+			case param.typ.Kind() == reflect.Struct:
+				param.kind = paramStruct
+				param.hasBody = true
+				hasBodyStruct = true
+			default:
+				param.kind = paramPath
+				param.pathIndex = pathIndex
+				pathIndex++
+			}
+
+			p.params[i] = param
+		}
+	})
+
+	return p.params
+}
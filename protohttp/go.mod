@@ -0,0 +1,11 @@
+module github.com/ixalender/bodyrest/protohttp
+
+go 1.23.2
+
+require (
+	github.com/ixalender/bodyrest v0.0.0
+	google.golang.org/genproto/googleapis/api v0.0.0-20240814211410-ddb44dafa142
+	google.golang.org/protobuf v1.34.2
+)
+
+replace github.com/ixalender/bodyrest => ../
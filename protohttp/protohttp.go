@@ -0,0 +1,113 @@
+// Package protohttp reads bodyrest transcoding rules from the google.api.http
+// annotations of protobuf service descriptors. It is a module of its own so
+// that bodyrest itself does not depend on protobuf.
+package protohttp
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ixalender/bodyrest"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Rules returns the rules annotated on the methods of service, handled by the
+// methods of srv with the same name, as generated for a gRPC server:
+//
+//	rules, err := protohttp.Rules(userspb.File_users_proto.Services().ByName("Users"), svc)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	bodyrest.Transcode(rt, rules...)
+//
+// Methods without an annotation are skipped; additional_bindings give rules
+// of their own.
+func Rules(service protoreflect.ServiceDescriptor, srv any) ([]bodyrest.HTTPRule, error) {
+	var rules []bodyrest.HTTPRule
+
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		opts := md.Options()
+		if opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+			continue
+		}
+
+		if md.IsStreamingClient() || md.IsStreamingServer() {
+			return nil, fmt.Errorf("method %s: streaming methods cannot be transcoded", md.FullName())
+		}
+
+		handler := reflect.ValueOf(srv).MethodByName(string(md.Name()))
+		if !handler.IsValid() {
+			return nil, fmt.Errorf("method %s: %T has no method %s", md.FullName(), srv, md.Name())
+		}
+
+		httpRule := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+		for _, binding := range append([]*annotations.HttpRule{httpRule}, httpRule.GetAdditionalBindings()...) {
+			rule, err := ruleOf(binding, handler.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("method %s: %w", md.FullName(), err)
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+func ruleOf(binding *annotations.HttpRule, handler any) (bodyrest.HTTPRule, error) {
+	var method, template string
+	switch pattern := binding.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, template = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		method, template = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		method, template = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		method, template = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		method, template = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		method, template = pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return bodyrest.HTTPRule{}, fmt.Errorf("http rule has no pattern")
+	}
+
+	pattern, err := chiPattern(template)
+	if err != nil {
+		return bodyrest.HTTPRule{}, err
+	}
+
+	return bodyrest.HTTPRule{
+		Method:  method,
+		Pattern: pattern,
+		Body:    binding.GetBody(),
+		Handler: handler,
+	}, nil
+}
+
+// chiPattern converts a google.api.http path template to a chi pattern.
+// Variables are single path segments, as in /v1/users/{user_id}; templates
+// binding a variable to several segments, as in {name=shelves/*}, and
+// wildcard segments have no chi equivalent.
+func chiPattern(template string) (string, error) {
+	if !strings.HasPrefix(template, "/") {
+		return "", fmt.Errorf("path template %q must start with /", template)
+	}
+
+	for _, segment := range strings.Split(template[1:], "/") {
+		if segment == "*" || segment == "**" {
+			return "", fmt.Errorf("path template %q: wildcard segments are not supported", template)
+		}
+		if strings.HasPrefix(segment, "{") && strings.Contains(segment, "=") {
+			return "", fmt.Errorf("path template %q: variables with segment patterns are not supported", template)
+		}
+	}
+
+	return template, nil
+}
@@ -0,0 +1,38 @@
+package protohttp
+
+import "testing"
+
+func TestChiPattern(t *testing.T) {
+	testCases := []struct {
+		name            string
+		template        string
+		expectedPattern string
+		expectedErr     bool
+	}{
+		{name: "literal", template: "/v1/users", expectedPattern: "/v1/users"},
+		{name: "variable", template: "/v1/users/{user_id}", expectedPattern: "/v1/users/{user_id}"},
+		{name: "nested variable", template: "/v1/orgs/{org}/users/{user.user_id}", expectedPattern: "/v1/orgs/{org}/users/{user.user_id}"},
+		{name: "segment pattern", template: "/v1/{name=shelves/*}", expectedErr: true},
+		{name: "wildcard", template: "/v1/files/**", expectedErr: true},
+		{name: "relative", template: "v1/users", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pattern, err := chiPattern(tc.template)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q, got pattern %q", tc.template, pattern)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if pattern != tc.expectedPattern {
+				t.Errorf("Expected pattern %q, got %q", tc.expectedPattern, pattern)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Provider creates handler parameters of type T with a lifecycle, e.g. a
+// database transaction. Begin runs once the request is bound; Commit runs
+// when the handler succeeded with a 2xx response and Rollback otherwise,
+// including when it panics. Commit and Rollback are optional.
+type Provider[T any] struct {
+	Begin    func(r *http.Request) (T, error)
+	Commit   func(v T) error
+	Rollback func(v T) error
+}
+
+type providerEntry struct {
+	begin    func(r *http.Request) (any, error)
+	commit   func(v any) error
+	rollback func(v any) error
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[reflect.Type]providerEntry{}
+)
+
+// RegisterProvider makes handlers of HandleTo able to take a T parameter
+// provided by p:
+//
+//	bodyrest.RegisterProvider(bodyrest.Provider[*sql.Tx]{
+//		Begin:    func(r *http.Request) (*sql.Tx, error) { return db.BeginTx(r.Context(), nil) },
+//		Commit:   (*sql.Tx).Commit,
+//		Rollback: (*sql.Tx).Rollback,
+//	})
+//
+// Begin errors are mapped through the error registry. T may be an interface,
+// so service-style handlers can take e.g. a storage.Blobs parameter:
+//
+//	bodyrest.RegisterProvider(bodyrest.Provider[storage.Blobs]{
+//		Begin: func(r *http.Request) (storage.Blobs, error) { return blobs, nil },
+//	})
+//
+// Register providers of interface types before the routes taking them, as
+// HandleTo fails on interface params with no provider.
+func RegisterProvider[T any](p Provider[T]) {
+	if p.Begin == nil {
+		log.Fatal("provider must have a Begin function")
+	}
+
+	entry := providerEntry{
+		begin: func(r *http.Request) (any, error) {
+			return p.Begin(r)
+		},
+		commit:   func(v any) error { return nil },
+		rollback: func(v any) error { return nil },
+	}
+	if p.Commit != nil {
+		entry.commit = func(v any) error { return p.Commit(v.(T)) }
+	}
+	if p.Rollback != nil {
+		entry.rollback = func(v any) error { return p.Rollback(v.(T)) }
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	providers[typeOf[T]()] = entry
+}
+
+func providerFor(t reflect.Type) (providerEntry, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	entry, ok := providers[t]
+	return entry, ok
+}
+
+// provideArgs is the outermost Invoker wrapper of every HandleTo route. It
+// begins the provided parameters of plan and calls next. The begun params
+// are settled by the providedArgs of the request once the response status
+// is known.
+func provideArgs(plan *bindPlan) func(next Invoker) Invoker {
+	return func(next Invoker) Invoker {
+		return func(r *http.Request, args []any) ([]any, error) {
+			provided, _ := r.Context().Value(providedArgsContextKey{}).(*providedArgs)
+			for i, param := range plan.paramPlans() {
+				if param.kind != paramProvided {
+					continue
+				}
+				if provided == nil {
+					return nil, errNoProvider
+				}
+
+				value, err := param.provider.begin(r)
+				if err != nil {
+					return nil, err
+				}
+				args[i] = value
+				provided.add(providedArg{entry: param.provider, value: value})
+			}
+
+			return next(r, args)
+		}
+	}
+}
+
+// providesParams reports whether the handler of p takes provided params.
+func (p *bindPlan) providesParams() bool {
+	for _, param := range p.paramPlans() {
+		if param.kind == paramProvided {
+			return true
+		}
+	}
+
+	return false
+}
+
+type providedArg struct {
+	entry providerEntry
+	value any
+}
+
+type providedArgsContextKey struct{}
+
+// providedArgs holds the provided params begun for a request until they are
+// committed or rolled back.
+type providedArgs struct {
+	mu      sync.Mutex
+	begun   []providedArg
+	settled bool
+}
+
+// withProvidedArgs returns r carrying the providedArgs the invoker adds the
+// params it begins to.
+func withProvidedArgs(r *http.Request) (*http.Request, *providedArgs) {
+	provided := &providedArgs{}
+	return r.WithContext(context.WithValue(r.Context(), providedArgsContextKey{}, provided)), provided
+}
+
+func (p *providedArgs) add(arg providedArg) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.begun = append(p.begun, arg)
+}
+
+// settle commits the begun params for a 2xx status and rolls them back
+// otherwise. A failed commit rolls back the params not committed yet.
+func (p *providedArgs) settle(status int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.settled {
+		return nil
+	}
+	p.settled = true
+
+	if status < 200 || status >= 300 {
+		rollbackArgs(p.begun)
+		return nil
+	}
+
+	for i, arg := range p.begun {
+		if err := arg.entry.commit(arg.value); err != nil {
+			rollbackArgs(p.begun[i+1:])
+			return err
+		}
+	}
+
+	return nil
+}
+
+// abort rolls back the params not settled yet, e.g. when the handler
+// panicked.
+func (p *providedArgs) abort() {
+	p.settle(http.StatusInternalServerError)
+}
+
+// detach marks the params settled and returns the function rolling them
+// back, for a handler still running after a timeout.
+func (p *providedArgs) detach() func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.settled {
+		return func() {}
+	}
+	p.settled = true
+
+	begun := p.begun
+	return func() { rollbackArgs(begun) }
+}
+
+// providedWriter settles the provided params of a request on the status of
+// its response. Results encoded by bodyrest are settled as the status is
+// written, once the handler has returned, so a failed commit replaces the
+// response with its error. Handlers returned by the handler are settled
+// after they have written the response, as they may still use the params
+// until then; a failed commit can then only be logged.
+type providedWriter struct {
+	http.ResponseWriter
+	r              *http.Request
+	provided       *providedArgs
+	settleOnHeader bool
+	status         int
+	failed         bool
+}
+
+func newProvidedWriter(w http.ResponseWriter, r *http.Request, provided *providedArgs, form resultForm) *providedWriter {
+	return &providedWriter{
+		ResponseWriter: w,
+		r:              r,
+		provided:       provided,
+		settleOnHeader: form != resultHandler && form != resultHandlerError,
+	}
+}
+
+func (w *providedWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+
+	if w.settleOnHeader {
+		if err := w.provided.settle(status); err != nil {
+			log.Printf("failed to commit provided parameter: %v\n", err)
+			w.failed = true
+			w.Header().Del("Content-Encoding")
+			w.Header().Del("Content-Length")
+			writeError(w.ResponseWriter, w.r, statusFromError(err), err)
+			return
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *providedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.failed {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *providedWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && !w.failed {
+		flusher.Flush()
+	}
+}
+
+func (w *providedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish settles the provided params once the response is written.
+func (w *providedWriter) finish() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if err := w.provided.settle(w.status); err != nil {
+		log.Printf("failed to commit provided parameter after the response was written: %v\n", err)
+	}
+}
+
+func rollbackArgs(args []providedArg) {
+	for i := len(args) - 1; i >= 0; i-- {
+		if err := args[i].entry.rollback(args[i].value); err != nil {
+			log.Printf("failed to roll back provided parameter: %v\n", err)
+		}
+	}
+}
+
+// errNoProvider is reported when a handler wrapped by anything but HandleTo
+// takes a provided parameter.
+var errNoProvider = errors.New("provided parameters are only supported by HandleTo")
+
+// checkProvidedParams reports the interface params of handlerType that are
+// neither injected nor provided, e.g. a storage.Blobs service registered
+// after the route, or never. Such params cannot be bound from the request.
+func checkProvidedParams(handlerType reflect.Type) error {
+	if handlerType == nil || handlerType.Kind() != reflect.Func {
+		return nil
+	}
+
+	for i := 0; i < handlerType.NumIn(); i++ {
+		t := handlerType.In(i)
+		if t.Kind() != reflect.Interface {
+			continue
+		}
+
+		_, injected := injectorFor(t)
+		_, provided := providerFor(t)
+		if !injected && !provided {
+			return fmt.Errorf("handler %s takes %s with no registered provider, call RegisterProvider before registering the route", handlerType, t)
+		}
+	}
+
+	return nil
+}
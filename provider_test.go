@@ -0,0 +1,314 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testTx struct {
+	state string
+}
+
+var (
+	errTestNoConnection = errors.New("no connection")
+	testTxs             []*testTx
+)
+
+func init() {
+	RegisterError(errTestNoConnection, http.StatusServiceUnavailable)
+
+	RegisterProvider(Provider[*testTx]{
+		Begin: func(r *http.Request) (*testTx, error) {
+			if r.Header.Get("X-Fail-Begin") != "" {
+				return nil, errTestNoConnection
+			}
+			tx := &testTx{state: "begun"}
+			testTxs = append(testTxs, tx)
+			return tx, nil
+		},
+		Commit: func(tx *testTx) error {
+			tx.state = "committed"
+			return nil
+		},
+		Rollback: func(tx *testTx) error {
+			tx.state = "rolled back"
+			return nil
+		},
+	})
+}
+
+func testSaveUser(tx *testTx, u testUser) (int, any, error) {
+	switch u.Name {
+	case "panic":
+		panic("boom")
+	case "invalid":
+		return http.StatusUnprocessableEntity, nil, nil
+	}
+
+	return testCreateUser(u)
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	testCases := []struct {
+		name          string
+		body          string
+		failBegin     bool
+		expectedCode  int
+		expectedState string
+	}{
+		{
+			name:          "committed on success",
+			body:          `{"name":"John"}`,
+			expectedCode:  http.StatusCreated,
+			expectedState: "committed",
+		},
+		{
+			name:          "rolled back on error",
+			body:          `{"name":"exists"}`,
+			expectedCode:  http.StatusConflict,
+			expectedState: "rolled back",
+		},
+		{
+			name:          "rolled back on non 2xx status",
+			body:          `{"name":"invalid"}`,
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedState: "rolled back",
+		},
+		{
+			name:          "rolled back on panic",
+			body:          `{"name":"panic"}`,
+			expectedCode:  http.StatusInternalServerError,
+			expectedState: "rolled back",
+		},
+		{
+			name:         "begin error",
+			body:         `{"name":"John"}`,
+			failBegin:    true,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "not begun when binding fails",
+			body:         `{"name":""}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTxs = nil
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.body))
+			if tc.failBegin {
+				req.Header.Set("X-Fail-Begin", "1")
+			}
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(testSaveUser))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if tc.expectedState == "" {
+				if len(testTxs) != 0 {
+					t.Errorf("Expected no transaction, got %d", len(testTxs))
+				}
+				return
+			}
+
+			if len(testTxs) != 1 || testTxs[0].state != tc.expectedState {
+				t.Errorf("Expected one transaction %s, got %+v", tc.expectedState, testTxs)
+			}
+		})
+	}
+}
+
+func TestProviderOutsideHandleTo(t *testing.T) {
+	req := httptest.NewRequest("GET", "/events", nil)
+
+	r := chi.NewRouter()
+	r.Get("/events", HandleSSE(func(tx *testTx, stream EventSink) error {
+		return nil
+	}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+type testBlobs interface {
+	Get(key string) (string, bool)
+}
+
+type testMemoryBlobs map[string]string
+
+func (b testMemoryBlobs) Get(key string) (string, bool) {
+	value, ok := b[key]
+	return value, ok
+}
+
+type testUnregisteredService interface {
+	Do() error
+}
+
+func TestInterfaceProvider(t *testing.T) {
+	RegisterProvider(Provider[testBlobs]{
+		Begin: func(r *http.Request) (testBlobs, error) {
+			return testMemoryBlobs{"a": "blob a"}, nil
+		},
+	})
+
+	rt := NewRouter(chi.NewRouter())
+	rt.Get("/blobs/{key}", func(blobs testBlobs, key string) (int, any, error) {
+		value, ok := blobs.Get(key)
+		if !ok {
+			return http.StatusNotFound, nil, nil
+		}
+		return http.StatusOK, value, nil
+	})
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{name: "provided", path: "/blobs/a", expectedCode: http.StatusOK},
+		{name: "not found", path: "/blobs/b", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestCheckProvidedParams(t *testing.T) {
+	testCases := []struct {
+		name        string
+		handler     any
+		expectedErr bool
+	}{
+		{name: "provided interface", handler: func(blobs testBlobs) error { return nil }},
+		{name: "injected interface", handler: func(ctx context.Context) error { return nil }},
+		{name: "unregistered interface", handler: func(s testUnregisteredService) error { return nil }, expectedErr: true},
+	}
+
+	RegisterProvider(Provider[testBlobs]{
+		Begin: func(r *http.Request) (testBlobs, error) { return testMemoryBlobs{}, nil },
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkProvidedParams(reflect.TypeOf(tc.handler))
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestProviderHandlerResult(t *testing.T) {
+	var stateInClosure string
+	handler := func(tx *testTx, u testUser) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			stateInClosure = tx.state
+			if u.Name == "fail" {
+				http.Error(w, "failed", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		expectedState string
+	}{
+		{name: "committed after the closure", body: `{"name":"John"}`, expectedCode: http.StatusCreated, expectedState: "committed"},
+		{name: "rolled back on closure failure", body: `{"name":"fail"}`, expectedCode: http.StatusInternalServerError, expectedState: "rolled back"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTxs, stateInClosure = nil, ""
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.body))
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if stateInClosure != "begun" {
+				t.Errorf("Expected the closure to use a begun transaction, got %q", stateInClosure)
+			}
+
+			if len(testTxs) != 1 || testTxs[0].state != tc.expectedState {
+				t.Errorf("Expected one transaction %s, got %+v", tc.expectedState, testTxs)
+			}
+		})
+	}
+}
+
+func TestProviderResponseStatus(t *testing.T) {
+	testTxs = nil
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"John"}`))
+
+	r := chi.NewRouter()
+	r.Post("/users", HandleTo(func(tx *testTx, u testUser) (Response, error) {
+		return Response{Status: http.StatusConflict, Body: u}, nil
+	}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if len(testTxs) != 1 || testTxs[0].state != "rolled back" {
+		t.Errorf("Expected one transaction rolled back, got %+v", testTxs)
+	}
+}
+
+type testFailingTx struct{}
+
+func TestProviderCommitError(t *testing.T) {
+	RegisterProvider(Provider[*testFailingTx]{
+		Begin:  func(r *http.Request) (*testFailingTx, error) { return &testFailingTx{}, nil },
+		Commit: func(tx *testFailingTx) error { return errTestNoConnection },
+	})
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"John"}`))
+	r := chi.NewRouter()
+	r.Post("/users", HandleTo(func(tx *testFailingTx, u testUser) (int, any, error) {
+		return http.StatusCreated, u, nil
+	}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "John") {
+		t.Errorf("Expected the response body to be replaced, got %s", w.Body.String())
+	}
+}
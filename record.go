@@ -0,0 +1,256 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxRecordedBody is the number of body bytes kept by the bind recorder.
+const maxRecordedBody = 1 << 20
+
+// redactedHeaders are never written by the bind recorder, along with the
+// API key header.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+var bindRecordDir string
+
+// SetBindRecorder saves every request failing to bind as a JSON file in
+// dir, to reproduce "works for the client, fails in bind" reports with
+// ReadRecordedRequest. Credentials headers, the API key and fields tagged
+// sensitive:"true" are redacted. An empty dir disables recording. It is
+// meant for development and debugging, not for production traffic.
+func SetBindRecorder(dir string) {
+	bindRecordDir = dir
+}
+
+// RecordedRequest is a request that failed to bind, as saved by the bind
+// recorder.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// URI is the path and query of the request.
+	URI string `json:"uri"`
+	// Params are the route params as key, value pairs in route order.
+	Params []string    `json:"params,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// Status is the status the request was answered with.
+	Status int `json:"status"`
+}
+
+// ReadRecordedRequest reads a request saved by the bind recorder.
+func ReadRecordedRequest(name string) (RecordedRequest, error) {
+	var rec RecordedRequest
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return rec, err
+	}
+
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+// Request rebuilds the recorded request with its route params, so tests can
+// feed it through the handler that failed to bind it:
+//
+//	rec, _ := bodyrest.ReadRecordedRequest("testdata/bind-failure.json")
+//	bodyrest.HandleTo(createUser).ServeHTTP(w, rec.Request())
+func (rec RecordedRequest) Request() *http.Request {
+	r, err := http.NewRequest(rec.Method, rec.URI, strings.NewReader(rec.Body))
+	if err != nil {
+		r, _ = http.NewRequest(rec.Method, "/", strings.NewReader(rec.Body))
+	}
+	r.Header = rec.Header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	return RequestWithParams(r, rec.Params...)
+}
+
+// bindRecording captures a request while it is bound.
+type bindRecording struct {
+	r    *http.Request
+	w    *statusRecorder
+	body *recordedBody
+}
+
+func startBindRecording(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *bindRecording) {
+	rec := &bindRecording{r: r, w: &statusRecorder{ResponseWriter: w}}
+	if r.Body != nil {
+		rec.body = &recordedBody{ReadCloser: r.Body}
+		r.Body = rec.body
+	}
+
+	return rec.w, rec
+}
+
+// save writes the recording to the recorder directory.
+func (rec *bindRecording) save(plan *bindPlan) {
+	saved := RecordedRequest{
+		Method: rec.r.Method,
+		URI:    redactedURI(rec.r.URL),
+		Header: redactedHeader(rec.r.Header),
+		Status: rec.w.statusCode(),
+	}
+
+	if rctx := chi.RouteContext(rec.r.Context()); rctx != nil {
+		for i, key := range rctx.URLParams.Keys {
+			saved.Params = append(saved.Params, key, rctx.URLParams.Values[i])
+		}
+	}
+
+	if rec.body != nil {
+		saved.Body = redactedBody(rec.body.remaining(), plan)
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		log.Printf("failed to encode recorded request: %v\n", err)
+		return
+	}
+
+	name := filepath.Join(bindRecordDir, fmt.Sprintf("bind-%s-%s.json", time.Now().UTC().Format("20060102T150405"), newErrorReference()))
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		log.Printf("failed to record request: %v\n", err)
+		return
+	}
+
+	log.Printf("recorded request failing to bind to %s\n", name)
+}
+
+// recordedBody keeps the first maxRecordedBody bytes read from a body.
+type recordedBody struct {
+	io.ReadCloser
+	data bytes.Buffer
+}
+
+func (b *recordedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxRecordedBody - b.data.Len(); room > 0 {
+		b.data.Write(p[:min(n, room)])
+	}
+
+	return n, err
+}
+
+// remaining reads what binding left of the body and returns the recorded
+// bytes.
+func (b *recordedBody) remaining() []byte {
+	io.Copy(io.Discard, io.LimitReader(b, int64(maxRecordedBody-b.data.Len())))
+	return b.data.Bytes()
+}
+
+func redactedURI(u *url.URL) string {
+	if apiKeyQuery == "" || !u.Query().Has(apiKeyQuery) {
+		return u.RequestURI()
+	}
+
+	query := u.Query()
+	query.Set(apiKeyQuery, redactedValue)
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.RequestURI()
+}
+
+func redactedHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range append([]string{apiKeyHeader}, redactedHeaders...) {
+		if name != "" && redacted.Get(name) != "" {
+			redacted.Set(name, redactedValue)
+		}
+	}
+
+	return redacted
+}
+
+// redactedBody returns body with the values of the sensitive fields of the
+// bound struct redacted. A body that is not a JSON object is dropped when
+// the struct has sensitive fields, as they cannot be told apart in it.
+func redactedBody(body []byte, plan *bindPlan) string {
+	var bodyType reflect.Type
+	for _, param := range plan.paramPlans() {
+		if param.kind == paramStruct && param.hasBody {
+			bodyType = param.typ
+		}
+	}
+	if bodyType == nil || !typeHasSensitiveFields(bodyType) {
+		return string(body)
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return redactedValue
+	}
+
+	redactObject(obj, bodyType)
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return redactedValue
+	}
+
+	return string(data)
+}
+
+// redactObject replaces the values of the sensitive fields of t in obj,
+// following nested structs.
+func redactObject(obj map[string]any, t reflect.Type) {
+	for _, field := range bodyFields(t) {
+		name := jsonFieldName(field)
+		value, ok := obj[name]
+		if !ok {
+			continue
+		}
+
+		if isSensitive(field) {
+			obj[name] = redactedValue
+			continue
+		}
+
+		redactNested(value, field.Type)
+	}
+}
+
+func redactNested(value any, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if t.Kind() == reflect.Struct {
+			redactObject(v, t)
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, elem := range v {
+				redactNested(elem, t.Elem())
+			}
+		}
+	}
+}
+
+func typeHasSensitiveFields(t reflect.Type) bool {
+	return searchType(t, func(t reflect.Type) bool {
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if isSensitive(t.Field(i)) {
+				return true
+			}
+		}
+		return false
+	}, map[reflect.Type]bool{})
+}
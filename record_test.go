@@ -0,0 +1,93 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestBindRecorder(t *testing.T) {
+	dir := t.TempDir()
+	SetBindRecorder(dir)
+	defer SetBindRecorder("")
+
+	login := func(tenant string, req testLoginRequest) (string, error) { return req.Username, nil }
+
+	r := chi.NewRouter()
+	r.Post("/tenants/{tenant}/login", HandleTo(login))
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Binds without recording",
+			body:           `{"username":"john","password":"secret"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Records failing bind",
+			body:           `{"username":1,"password":"secret","card":{"number":"4242","brand":"visa"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"card":{"brand":"visa","number":"[REDACTED]"},"password":"[REDACTED]","username":1}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/tenants/acme/login?debug=1", strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			files, err := filepath.Glob(filepath.Join(dir, "bind-*.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedBody == "" {
+				if len(files) != 0 {
+					t.Errorf("Expected no recorded requests, got %v", files)
+				}
+				return
+			}
+			if len(files) != 1 {
+				t.Fatalf("Expected 1 recorded request, got %v", files)
+			}
+
+			rec, err := ReadRecordedRequest(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := RecordedRequest{
+				Method: "POST",
+				URI:    "/tenants/acme/login?debug=1",
+				Params: []string{"tenant", "acme"},
+				Header: http.Header{"Authorization": {"[REDACTED]"}, "Content-Type": {"application/json"}},
+				Body:   tc.expectedBody,
+				Status: http.StatusBadRequest,
+			}
+			if !reflect.DeepEqual(rec, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, rec)
+			}
+
+			w = httptest.NewRecorder()
+			HandleTo(login).ServeHTTP(w, rec.Request())
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected replayed status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package bodyrest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// redactedValue replaces the value of sensitive fields.
+const redactedValue = "[REDACTED]"
+
+// isSensitive reports whether field is tagged sensitive:"true". Values of
+// such fields never appear in bodyrest logs, error details or messages.
+func isSensitive(field reflect.StructField) bool {
+	sensitive, _ := strconv.ParseBool(field.Tag.Get("sensitive"))
+	return sensitive
+}
+
+// Redact returns a JSON-like copy of v (maps, slices and scalars) with the
+// values of fields tagged sensitive:"true" replaced, for logging bound
+// requests safely.
+func Redact(v any) any {
+	return redactValue(reflect.ValueOf(v))
+}
+
+func redactValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	// values encoding themselves, e.g. time.Time, keep their encoding unless
+	// they hold sensitive fields
+	if marshaler, ok := marshalerOf(v); ok && !typeHasTag(v.Type(), "sensitive") {
+		return marshaler
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem())
+	case reflect.Struct:
+		out := map[string]any{}
+		redactStruct(v, out)
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value())
+		}
+		return out
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+}
+
+func redactStruct(v reflect.Value, out map[string]any) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if isEmbeddedStruct(field) {
+			redactStruct(v.Field(i), out)
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if isSensitive(field) {
+			out[name] = redactedValue
+			continue
+		}
+
+		out[name] = redactValue(v.Field(i))
+	}
+}
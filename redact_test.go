@@ -0,0 +1,86 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password" sensitive:"true"`
+	Card     *struct {
+		Number string `json:"number" sensitive:"true"`
+		Brand  string `json:"brand"`
+	} `json:"card,omitempty"`
+	Pin int `query:"pin" json:"-" sensitive:"true"`
+}
+
+func TestRedact(t *testing.T) {
+	req := testLoginRequest{Username: "john", Password: "secret"}
+	req.Card = &struct {
+		Number string `json:"number" sensitive:"true"`
+		Brand  string `json:"brand"`
+	}{Number: "4242", Brand: "visa"}
+
+	encoded, err := json.Marshal(Redact(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"card":{"brand":"visa","number":"[REDACTED]"},"password":"[REDACTED]","username":"john"}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+}
+
+func TestRedactMarshalers(t *testing.T) {
+	req := struct {
+		Username string    `json:"username"`
+		At       time.Time `json:"at"`
+	}{Username: "john", At: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)}
+
+	encoded, err := json.Marshal(Redact(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"at":"2024-05-01T09:30:00Z","username":"john"}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+}
+
+func TestSensitiveBindErrors(t *testing.T) {
+	var reported error
+	saved := restErrorFunc
+	restErrorFunc = func(w http.ResponseWriter, r *http.Request, status int) {
+		reported = ErrorFromRequest(r)
+		w.WriteHeader(status)
+	}
+	defer func() { restErrorFunc = saved }()
+
+	req := httptest.NewRequest("GET", "/login?pin=my-secret-pin", nil)
+
+	r := chi.NewRouter()
+	r.Get("/login", HandleTo(func(q struct {
+		Pin int `query:"pin" sensitive:"true"`
+	}) (int, any, error) {
+		return http.StatusNoContent, nil, nil
+	}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if reported == nil || strings.Contains(reported.Error(), "my-secret-pin") {
+		t.Errorf("Expected error without the sensitive value, got %v", reported)
+	}
+}
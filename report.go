@@ -0,0 +1,65 @@
+package bodyrest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// ErrorReporterFunc receives 5xx failures and recovered panics. stack is set
+// for panics only.
+type ErrorReporterFunc func(r *http.Request, err error, stack []byte)
+
+var errorReporter ErrorReporterFunc
+
+// SetErrorReporter sets the hook that forwards server errors and recovered
+// handler panics to an error tracker.
+func SetErrorReporter(reporter ErrorReporterFunc) {
+	errorReporter = reporter
+}
+
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func reportError(r *http.Request, status int, err error) {
+	if errorReporter == nil {
+		return
+	}
+
+	if err == nil {
+		err = errors.New(http.StatusText(status))
+	}
+
+	var stack []byte
+	var pe *panicError
+	if errors.As(err, &pe) {
+		stack = pe.stack
+	}
+
+	errorReporter(r, err, stack)
+}
+
+// recoverHandler turns a panic in a bound handler into a 500 response. It
+// must be deferred.
+func recoverHandler(w http.ResponseWriter, r *http.Request) {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	if value == http.ErrAbortHandler {
+		panic(value)
+	}
+
+	err := &panicError{value: value, stack: debug.Stack()}
+	log.Printf("handler panicked: %v\n%s", value, err.stack)
+	writeError(w, r, http.StatusInternalServerError, err)
+}
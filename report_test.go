@@ -0,0 +1,67 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testPanickingHandler(id int) http.HandlerFunc {
+	panic("boom")
+}
+
+func TestErrorReporter(t *testing.T) {
+	type report struct {
+		err      error
+		hasStack bool
+	}
+	var reports []report
+	SetErrorReporter(func(r *http.Request, err error, stack []byte) {
+		reports = append(reports, report{err: err, hasStack: len(stack) > 0})
+	})
+	defer SetErrorReporter(nil)
+
+	testCases := []struct {
+		name           string
+		path           string
+		handler        interface{}
+		expectedStatus int
+		expectedReport bool
+		expectedStack  bool
+	}{
+		{name: "Recovered panic", path: "/test/1", handler: testPanickingHandler, expectedStatus: http.StatusInternalServerError, expectedReport: true, expectedStack: true},
+		{name: "Server error", path: "/test/1", handler: func(id int) (string, error) { return "", errors.New("downstream failed") }, expectedStatus: http.StatusInternalServerError, expectedReport: true},
+		{name: "Client error", path: "/test/abc", handler: testPanickingHandler, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reports = nil
+
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/test/{id}", HandleTo(tc.handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if (len(reports) == 1) != tc.expectedReport {
+				t.Fatalf("Expected report %v, got %d reports", tc.expectedReport, len(reports))
+			}
+
+			if tc.expectedReport && reports[0].hasStack != tc.expectedStack {
+				t.Errorf("Expected stack %v, got %v", tc.expectedStack, reports[0].hasStack)
+			}
+		})
+	}
+}
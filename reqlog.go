@@ -0,0 +1,114 @@
+package bodyrest
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+var requestLog struct {
+	logger *slog.Logger
+	rate   float64
+}
+
+// SetRequestLogger logs a sample of the requests handled by HandleTo to
+// logger once their response is written: the route, status, duration and a
+// summary of the bound params. rate is the fraction of requests logged, from
+// 0 to 1. A nil logger disables request logging.
+func SetRequestLogger(logger *slog.Logger, rate float64) {
+	requestLog.logger = logger
+	requestLog.rate = rate
+}
+
+// WithRequestLogging overrides the sampling rate of SetRequestLogger for the
+// route, e.g. 0 to never log a health check or 1 to log every request to a
+// sensitive endpoint.
+func WithRequestLogging(rate float64) Option {
+	return func(cfg *routeConfig) {
+		cfg.logRate = &rate
+	}
+}
+
+// requestLogRecord collects what is logged about a single request. A nil
+// record logs nothing.
+type requestLogRecord struct {
+	logger *slog.Logger
+	w      *statusRecorder
+	r      *http.Request
+	start  time.Time
+	args   []any
+}
+
+// startRequestLog returns the writer to respond with and the log record of
+// r, or w itself and nil when r is not sampled.
+func startRequestLog(w http.ResponseWriter, r *http.Request, cfg *routeConfig) (http.ResponseWriter, *requestLogRecord) {
+	logger, rate := requestLog.logger, requestLog.rate
+	if cfg.logRate != nil {
+		rate = *cfg.logRate
+	}
+	if logger == nil || rate <= 0 || (rate < 1 && rand.Float64() >= rate) {
+		return w, nil
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	return rec, &requestLogRecord{logger: logger, w: rec, r: r, start: time.Now()}
+}
+
+func (l *requestLogRecord) setArgs(args []reflect.Value) {
+	if l == nil {
+		return
+	}
+
+	l.args = make([]any, len(args))
+	for i, arg := range args {
+		l.args[i] = arg.Interface()
+	}
+}
+
+func (l *requestLogRecord) finish(plan *bindPlan) {
+	if l == nil {
+		return
+	}
+
+	status := l.w.statusCode()
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+
+	l.logger.LogAttrs(l.r.Context(), level, "request",
+		slog.String("method", l.r.Method),
+		slog.String("route", routePattern(l.r)),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(l.start)),
+		slog.Any("params", paramSummary(plan, l.args)),
+	)
+}
+
+// paramSummary describes the bound params without their content: the values
+// of path params and the types of the others. Injected and provided params
+// are left out.
+func paramSummary(plan *bindPlan, args []any) []string {
+	if len(args) == 0 {
+		return nil
+	}
+
+	var summary []string
+	for i, param := range plan.paramPlans() {
+		if i >= len(args) {
+			break
+		}
+
+		switch param.kind {
+		case paramPath:
+			summary = append(summary, fmt.Sprint(args[i]))
+		case paramStruct, paramMultipart:
+			summary = append(summary, param.typ.String())
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,69 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSetRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetRequestLogger(slog.New(slog.NewJSONHandler(&buf, nil)), 1)
+	defer SetRequestLogger(nil, 0)
+
+	handler := func(org string, u testUser) (testUser, error) {
+		return u, nil
+	}
+
+	r := chi.NewRouter()
+	r.Post("/orgs/{org}/users", HandleTo(handler))
+	r.Post("/quiet/{org}", HandleTo(handler, WithRequestLogging(0)))
+
+	testCases := []struct {
+		name           string
+		path           string
+		body           string
+		expectedLogged bool
+		expectedStatus float64
+		expectedParams []any
+	}{
+		{name: "logged", path: "/orgs/acme/users", body: `{"name":"john"}`, expectedLogged: true, expectedStatus: 200, expectedParams: []any{"acme", "bodyrest.testUser"}},
+		{name: "bind failure", path: "/orgs/acme/users", body: `{"name":`, expectedLogged: true, expectedStatus: 400},
+		{name: "disabled for route", path: "/quiet/acme", body: `{"name":"john"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body)))
+
+			if !tc.expectedLogged {
+				if buf.Len() != 0 {
+					t.Errorf("Expected nothing logged, got %s", buf.String())
+				}
+				return
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+			}
+
+			if entry["route"] != "/orgs/{org}/users" || entry["status"] != tc.expectedStatus {
+				t.Errorf("Expected route /orgs/{org}/users with status %v, got %v", tc.expectedStatus, entry)
+			}
+
+			params, _ := entry["params"].([]any)
+			if !reflect.DeepEqual(params, tc.expectedParams) {
+				t.Errorf("Expected params %v, got %v", tc.expectedParams, entry["params"])
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package bodyrest
+
+import (
+	"log"
+	"net/http"
+	"reflect"
+)
+
+type resultForm int
+
+const (
+	resultInvalid resultForm = iota
+	resultHandler
+	resultHandlerError
+	resultStatusBody
+	resultResponse
+	resultValue
+	resultJob
+)
+
+// Response describes a response that bodyrest encodes on behalf of a
+// handler. A zero Status means 200 and a nil Body writes no content.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   any
+}
+
+var responseType = reflect.TypeOf(Response{})
+
+// Created returns a 201 response with the Location header set to location.
+func Created(location string, body any) Response {
+	return Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Location": []string{location}},
+		Body:   body,
+	}
+}
+
+// WithHeaders returns a response with body v and headers added, for handlers
+// that return a value or a status and body but need to set e.g.
+// Cache-Control or rate limit headers. If v is a Response the headers are
+// added to it.
+func WithHeaders(v any, headers map[string]string) Response {
+	resp, ok := v.(Response)
+	if !ok {
+		resp = Response{Body: v}
+	}
+
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	resp.Header = header
+
+	return resp
+}
+
+// responseOf builds the response for a handler result body with status. A
+// body that is itself a Response, e.g. from WithHeaders, keeps its headers
+// and overrides status if it sets one.
+func responseOf(status int, body reflect.Value) Response {
+	if isNilValue(body) {
+		return Response{Status: status}
+	}
+
+	resp, ok := body.Interface().(Response)
+	if !ok {
+		return Response{Status: status, Body: body.Interface()}
+	}
+
+	if resp.Status == 0 {
+		resp.Status = status
+	}
+	return resp
+}
+
+var httpHandlerType = reflect.TypeOf((*http.Handler)(nil)).Elem()
+
+func resultFormOf(handlerType reflect.Type) resultForm {
+	switch {
+	case handlerType.NumOut() == 1 && handlerType.Out(0).Implements(httpHandlerType):
+		return resultHandler
+	case handlerType.NumOut() == 2 && handlerType.Out(0).Implements(httpHandlerType) && handlerType.Out(1) == errorType:
+		return resultHandlerError
+	case handlerType.NumOut() == 2 && handlerType.Out(0) == responseType && handlerType.Out(1) == errorType:
+		return resultResponse
+	case handlerType.NumOut() == 3 && handlerType.Out(0).Kind() == reflect.Int && handlerType.Out(2) == errorType:
+		return resultStatusBody
+	case handlerType.NumOut() == 2 && handlerType.Out(0) == jobType && handlerType.Out(1) == errorType:
+		return resultJob
+	case handlerType.NumOut() == 2 && handlerType.Out(1) == errorType:
+		return resultValue
+	}
+
+	return resultInvalid
+}
+
+func writeResults(w http.ResponseWriter, r *http.Request, cfg *routeConfig, form resultForm, results []reflect.Value) {
+	switch form {
+	case resultHandler:
+		serveResultHandler(w, r, cfg, results[0])
+	case resultHandlerError:
+		if err, _ := results[1].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		serveResultHandler(w, r, cfg, results[0])
+	case resultResponse:
+		if err, _ := results[1].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		writeResponse(w, r, cfg, results[0].Interface().(Response))
+	case resultStatusBody:
+		if err, _ := results[2].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		writeResponse(w, r, cfg, responseOf(int(results[0].Int()), results[1]))
+	case resultValue:
+		if err, _ := results[1].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		writeResponse(w, r, cfg, responseOf(http.StatusOK, results[0]))
+	case resultJob:
+		if err, _ := results[1].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		writeResponse(w, r, cfg, acceptedJob(results[0].Interface().(Job)))
+	default:
+		log.Println("handler does not return http.Handler")
+		writeError(w, r, http.StatusInternalServerError, nil)
+	}
+}
+
+func serveResultHandler(w http.ResponseWriter, r *http.Request, cfg *routeConfig, result reflect.Value) {
+	if isNilValue(result) {
+		log.Println("handler returned nil http.Handler")
+		writeError(w, r, http.StatusInternalServerError, nil)
+		return
+	}
+
+	handler, ok := result.Interface().(http.Handler)
+	if !ok {
+		log.Println("handler does not return http.Handler")
+		writeError(w, r, http.StatusInternalServerError, nil)
+		return
+	}
+
+	handler.ServeHTTP(w, withResponseShape(r, cfg))
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Func, reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, cfg *routeConfig, resp Response) {
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var payload []byte
+	if resp.Body != nil {
+		var err error
+		payload, err = marshalShaped(resp.Body, true, responseShapeOf(r, cfg))
+		if err != nil {
+			log.Printf("failed to encode response body: %v\n", err)
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		fields, err := requestedFields(r, cfg)
+		if err == nil && len(fields) > 0 {
+			payload, err = maskPayload(payload, fields)
+		}
+		if err != nil {
+			log.Printf("failed to mask response body: %v\n", err)
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	if cfg.cacheControl != "" && status >= 200 && status < 300 && w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", cfg.cacheControl)
+	}
+
+	if payload == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	payload = compressPayload(w, r, append(payload, '\n'))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(payload); err != nil {
+		log.Printf("failed to write response body: %v\n", err)
+	}
+}
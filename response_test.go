@@ -0,0 +1,149 @@
+package bodyrest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testUser struct {
+	Name string `json:"name"`
+}
+
+var errTestUserExists = errors.New("user exists")
+
+func testCreateUser(u testUser) (int, any, error) {
+	switch u.Name {
+	case "exists":
+		return 0, nil, errTestUserExists
+	case "empty":
+		return http.StatusNoContent, nil, nil
+	}
+
+	return http.StatusCreated, u, nil
+}
+
+func testCreateUserResponse(u testUser) (Response, error) {
+	return Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"X-User": []string{u.Name}},
+		Body:   u,
+	}, nil
+}
+
+func testCreateUserCreated(u testUser) (Response, error) {
+	return Created("/users/"+u.Name, u), nil
+}
+
+func TestHandleToResponses(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	testCases := []struct {
+		name            string
+		jsonPayload     string
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+		handler         interface{}
+	}{
+		{
+			name:            "Status and body",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusCreated,
+			expectedBody:    `{"name":"john"}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json"},
+			handler:         testCreateUser,
+		},
+		{
+			name:           "Status without body",
+			jsonPayload:    `{"name":"empty"}`,
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   ``,
+			handler:        testCreateUser,
+		},
+		{
+			name:           "Status with error",
+			jsonPayload:    `{"name":"exists"}`,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"message":"Something went wrong. Please try again later."}`,
+			handler:        testCreateUser,
+		},
+		{
+			name:            "Response struct",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusCreated,
+			expectedBody:    `{"name":"john"}`,
+			expectedHeaders: map[string]string{"X-User": "john"},
+			handler:         testCreateUserResponse,
+		},
+		{
+			name:            "Value and error",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"name":"JOHN"}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json"},
+			handler:         testImportUser,
+		},
+		{
+			name:            "Created response",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusCreated,
+			expectedBody:    `{"name":"john"}`,
+			expectedHeaders: map[string]string{"Location": "/users/john", "Content-Type": "application/json"},
+			handler:         testCreateUserCreated,
+		},
+		{
+			name:            "Value with headers",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"name":"john"}`,
+			expectedHeaders: map[string]string{"Cache-Control": "max-age=60", "Content-Type": "application/json"},
+			handler: func(u testUser) (any, error) {
+				return WithHeaders(u, map[string]string{"Cache-Control": "max-age=60"}), nil
+			},
+		},
+		{
+			name:            "Status and body with headers",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusCreated,
+			expectedBody:    `{"name":"john"}`,
+			expectedHeaders: map[string]string{"X-RateLimit-Remaining": "9", "Location": "/users/john"},
+			handler: func(u testUser) (int, any, error) {
+				return http.StatusCreated, WithHeaders(Created("/users/"+u.Name, u), map[string]string{"X-RateLimit-Remaining": "9"}), nil
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(tc.handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != strings.TrimSpace(tc.expectedBody) {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+
+			for key, value := range tc.expectedHeaders {
+				if w.Header().Get(key) != value {
+					t.Errorf("Expected header %s to be %s, got %s", key, value, w.Header().Get(key))
+				}
+			}
+		})
+	}
+}
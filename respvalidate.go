@@ -0,0 +1,165 @@
+package bodyrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// ResponseValidation sets what happens when a response does not match the
+// declared response type of its route.
+type ResponseValidation int
+
+const (
+	// ResponseValidationOff skips the check, the default.
+	ResponseValidationOff ResponseValidation = iota
+	// ResponseValidationLog logs mismatches and sends the response as is.
+	ResponseValidationLog
+	// ResponseValidationFail logs mismatches and answers 500 instead.
+	ResponseValidationFail
+)
+
+var responseValidation = ResponseValidationOff
+
+// SetResponseValidation enables checking successful JSON responses against
+// the declared response type of their route: the type set with
+// WithResponseType, the concrete type returned by the handler or the type of
+// the first example response. Responses with fields the type does not have,
+// or values of the wrong JSON type, are mismatches; _links members and times
+// in the SetTimeFormat layout are not. Responses are buffered while the check
+// is on, so it is meant for development and tests.
+func SetResponseValidation(mode ResponseValidation) {
+	responseValidation = mode
+}
+
+// WithResponseType declares the type of the route's response body by a
+// value of it, e.g. WithResponseType(User{}), for handlers returning any or
+// writing JSON themselves.
+func WithResponseType(v any) Option {
+	return func(cfg *routeConfig) {
+		cfg.responseType = reflect.TypeOf(v)
+	}
+}
+
+// declaredResponseType returns the type responses of a handler are checked
+// against, or nil if it cannot be told.
+func declaredResponseType(handlerType reflect.Type, form resultForm, cfg *routeConfig) reflect.Type {
+	if cfg.responseType != nil {
+		return cfg.responseType
+	}
+
+	var declared reflect.Type
+	switch form {
+	case resultValue:
+		declared = handlerType.Out(0)
+	case resultStatusBody:
+		declared = handlerType.Out(1)
+	}
+	if declared != nil && declared.Kind() != reflect.Interface {
+		return declared
+	}
+
+	if len(cfg.examples) > 0 && cfg.examples[0].Response != nil {
+		return reflect.TypeOf(cfg.examples[0].Response)
+	}
+
+	return nil
+}
+
+// validatingResponse returns respond with its response checked against t
+// before it is sent.
+func validatingResponse(r *http.Request, t reflect.Type, respond func(w http.ResponseWriter)) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		buf := &bufferedResponseWriter{header: w.Header()}
+		respond(buf)
+
+		if err := checkResponse(buf, t); err != nil {
+			log.Printf("response of %s %s does not match %s: %v\n", r.Method, r.URL.Path, t, err)
+			if responseValidation == ResponseValidationFail {
+				w.Header().Del("Content-Encoding")
+				writeError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		w.WriteHeader(buf.statusCode())
+		if _, err := w.Write(buf.body.Bytes()); err != nil {
+			log.Printf("failed to write response body: %v\n", err)
+		}
+	}
+}
+
+// checkResponse decodes a successful JSON response into a value of t,
+// rejecting unknown fields.
+func checkResponse(buf *bufferedResponseWriter, t reflect.Type) error {
+	status := buf.statusCode()
+	if status < 200 || status >= 300 || buf.body.Len() == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(buf.header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	var body io.Reader = bytes.NewReader(buf.body.Bytes())
+	if buf.header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return err
+		}
+		body = zr
+	}
+
+	var doc any
+	dec := json.NewDecoder(body)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return newDecodeError(err)
+	}
+
+	normalized, err := json.Marshal(unshapeResponse(doc))
+	if err != nil {
+		return err
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(normalized))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(reflect.New(t).Interface()); err != nil {
+		return newDecodeError(err)
+	}
+
+	return nil
+}
+
+// unshapeResponse undoes what bodyrest adds when encoding a response before
+// it is checked: _links members are dropped and, with SetTimeFormat, times
+// are turned back into RFC 3339.
+func unshapeResponse(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		delete(v, "_links")
+		for key, value := range v {
+			v[key] = unshapeResponse(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = unshapeResponse(value)
+		}
+	case string:
+		if timeFormat == "" {
+			return v
+		}
+		if at, err := time.Parse(timeFormat, v); err == nil {
+			return at.Format(time.RFC3339Nano)
+		}
+	}
+
+	return v
+}
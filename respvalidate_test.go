@@ -0,0 +1,77 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseValidation(t *testing.T) {
+	adHoc := func() http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"john","nickname":"jo"}`))
+		})
+	}
+
+	testCases := []struct {
+		name           string
+		mode           ResponseValidation
+		handler        interface{}
+		opts           []Option
+		expectedStatus int
+		expectedField  string
+	}{
+		{name: "matching value", mode: ResponseValidationFail, handler: func() (testUser, error) { return testUser{Name: "john"}, nil }, expectedStatus: http.StatusOK},
+		{name: "ad-hoc json fails", mode: ResponseValidationFail, handler: adHoc, opts: []Option{WithResponseType(testUser{})}, expectedStatus: http.StatusInternalServerError},
+		{name: "ad-hoc json logged", mode: ResponseValidationLog, handler: adHoc, opts: []Option{WithResponseType(testUser{})}, expectedStatus: http.StatusOK},
+		{name: "ad-hoc json unchecked", mode: ResponseValidationOff, handler: adHoc, opts: []Option{WithResponseType(testUser{})}, expectedStatus: http.StatusOK},
+		{
+			name:           "any checked against example",
+			mode:           ResponseValidationFail,
+			handler:        func() (any, error) { return map[string]any{"name": 1}, nil },
+			opts:           []Option{WithExample(nil, testUser{Name: "john"})},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{name: "links", mode: ResponseValidationFail, handler: func() (testOrgMember, error) { return testOrgMember{Org: "acme", ID: 1}, nil }, expectedStatus: http.StatusOK, expectedField: "org"},
+		{
+			name:           "time format",
+			mode:           ResponseValidationFail,
+			handler:        func() (testEvent, error) { return testEvent{Title: "launch", At: time.Now()}, nil },
+			opts:           []Option{WithResponseType(testEvent{})},
+			expectedStatus: http.StatusOK,
+			expectedField:  "title",
+		},
+		{name: "undeclared any", mode: ResponseValidationFail, handler: func() (any, error) { return map[string]any{"name": 1}, nil }, expectedStatus: http.StatusOK},
+	}
+
+	SetTimeFormat(time.DateTime)
+	defer SetTimeFormat("")
+	defer SetResponseValidation(ResponseValidationOff)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetResponseValidation(tc.mode)
+			handler := HandleTo(tc.handler, tc.opts...)
+
+			req := httptest.NewRequest("GET", "/users/me", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			field := tc.expectedField
+			if field == "" {
+				field = "name"
+			}
+			if w.Code == http.StatusOK && !strings.Contains(w.Body.String(), field) {
+				t.Errorf("Expected response body to be sent, got %q", w.Body.String())
+			}
+		})
+	}
+}
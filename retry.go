@@ -0,0 +1,68 @@
+package bodyrest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrServiceUnavailable rejects a request with 503, e.g. during maintenance
+// or when a dependency is down.
+var ErrServiceUnavailable = errors.New("service unavailable")
+
+func init() {
+	RegisterError(ErrServiceUnavailable, http.StatusServiceUnavailable)
+}
+
+// RetryAfterError carries the time after which a rejected request may be
+// retried. It is written as the Retry-After header, in seconds if After is
+// set and as an HTTP date otherwise; the status comes from Err.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+	At    time.Time
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.At.IsZero() {
+		return fmt.Sprintf("%v: retry after %s", e.Err, e.After)
+	}
+
+	return fmt.Sprintf("%v: retry at %s", e.Err, e.At.UTC().Format(time.RFC3339))
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter wraps err, e.g. ErrTooManyRequests or ErrServiceUnavailable, so
+// that the response tells clients to retry after d.
+func RetryAfter(err error, d time.Duration) error {
+	return &RetryAfterError{Err: err, After: d}
+}
+
+// RetryAt wraps err so that the response tells clients to retry at t.
+func RetryAt(err error, t time.Time) error {
+	return &RetryAfterError{Err: err, At: t}
+}
+
+// setRetryAfter sets the Retry-After header if err carries a retry time.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var retry *RetryAfterError
+	if !errors.As(err, &retry) {
+		return
+	}
+
+	if !retry.At.IsZero() {
+		w.Header().Set("Retry-After", retry.At.UTC().Format(http.TimeFormat))
+		return
+	}
+
+	seconds := int64(0)
+	if retry.After > 0 {
+		seconds = int64((retry.After + time.Second - 1) / time.Second)
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
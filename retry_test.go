@@ -0,0 +1,44 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name               string
+		err                error
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{name: "throttled", err: RetryAfter(ErrTooManyRequests, 1500*time.Millisecond), expectedStatus: http.StatusTooManyRequests, expectedRetryAfter: "2"},
+		{name: "maintenance", err: RetryAt(ErrServiceUnavailable, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)), expectedStatus: http.StatusServiceUnavailable, expectedRetryAfter: "Fri, 02 Jan 2026 03:04:05 GMT"},
+		{name: "wrapped", err: errors.Join(errors.New("queue full"), RetryAfter(ErrServiceUnavailable, time.Minute)), expectedStatus: http.StatusServiceUnavailable, expectedRetryAfter: "60"},
+		{name: "without retry time", err: ErrTooManyRequests, expectedStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := HandleTo(testGetUserStatus, WithGate(func(ctx context.Context, key string) error {
+				return tc.err
+			}, nil))
+
+			req := httptest.NewRequest("GET", "/users/1/status", nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, RequestWithParams(req, "id", "1"))
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if retryAfter := w.Header().Get("Retry-After"); retryAfter != tc.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tc.expectedRetryAfter, retryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package bodyrest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Route identifies the route matched by a request. It can be taken as a
+// handler parameter, e.g. to label metrics or build links to the resource
+// without hard-coding paths; hooks receiving the request use RouteOf.
+type Route struct {
+	Method string
+	// Pattern is the matched route pattern, empty when the request was not
+	// routed by a pattern.
+	Pattern string
+	// Name is the name given with WithRouteName, if any.
+	Name string
+}
+
+type routeNameContextKey struct{}
+
+func init() {
+	registerInjector(typeOf[Route](), func(r *http.Request) (reflect.Value, error) {
+		return reflect.ValueOf(RouteOf(r)), nil
+	})
+}
+
+// WithRouteName names the route, for Route.Name. Routes registered through a
+// Router can then be reversed with URLFor.
+func WithRouteName(name string) Option {
+	return func(cfg *routeConfig) {
+		cfg.routeName = name
+	}
+}
+
+// RouteOf returns the route matched by r.
+func RouteOf(r *http.Request) Route {
+	name, _ := r.Context().Value(routeNameContextKey{}).(string)
+	return Route{Method: r.Method, Pattern: matchedPattern(r), Name: name}
+}
+
+// withRouteName returns r carrying the route name, or r itself when the
+// route has none.
+func withRouteName(r *http.Request, name string) *http.Request {
+	if name == "" {
+		return r
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), routeNameContextKey{}, name))
+}
+
+// matchedPattern returns the chi route pattern of r, or the path of the
+// http.ServeMux pattern that matched it.
+func matchedPattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+
+	if r.Pattern == "" {
+		return ""
+	}
+
+	// ServeMux patterns are [METHOD ][HOST]/PATH.
+	_, path, _ := strings.Cut(r.Pattern, "/")
+	return "/" + path
+}
+
+var (
+	namedRoutesMu sync.RWMutex
+	namedRoutes   = map[string]string{}
+)
+
+// nameRoute records the pattern of a named route for URLFor. A name can only
+// be given to one pattern.
+func nameRoute(name, pattern string) error {
+	namedRoutesMu.Lock()
+	defer namedRoutesMu.Unlock()
+
+	if registered, ok := namedRoutes[name]; ok && registered != pattern {
+		return fmt.Errorf("route name %q is already used by %s", name, registered)
+	}
+
+	namedRoutes[name] = pattern
+	return nil
+}
+
+// URLFor builds the path of the route registered through a Router under
+// name, filling its placeholders in order with the escaped params, e.g.
+// URLFor("user.get", 42) for "/users/{id}". It fails for unknown names, for
+// a wrong number of params and for params not matching the regexp
+// constraint of their placeholder. The pattern is the one given to the
+// Router, without the prefix it may be mounted at.
+func URLFor(name string, params ...any) (string, error) {
+	namedRoutesMu.RLock()
+	pattern, ok := namedRoutes[name]
+	namedRoutesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+
+	placeholders := parsePlaceholders(pattern)
+	if len(params) != len(placeholders) {
+		return "", fmt.Errorf("route %q: %d params given for %d placeholders of %s", name, len(params), len(placeholders), pattern)
+	}
+
+	for i, p := range placeholders {
+		if p.constraint == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("^(?:" + p.constraint + ")$")
+		if err != nil {
+			return "", fmt.Errorf("route %q: placeholder {%s}: %w", name, p.name, err)
+		}
+		if !re.MatchString(fmt.Sprint(params[i])) {
+			return "", fmt.Errorf("route %q: param %v does not match {%s:%s}", name, params[i], p.name, p.constraint)
+		}
+	}
+
+	return Href(pattern, params...), nil
+}
@@ -0,0 +1,118 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testGetRoutedUser(route Route, id int) (Route, error) {
+	return route, nil
+}
+
+func TestRouteParam(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mux          func() http.Handler
+		path         string
+		expectedBody string
+	}{
+		{
+			name: "Named chi route",
+			mux: func() http.Handler {
+				r := chi.NewRouter()
+				r.Get("/users/{id}", HandleTo(testGetRoutedUser, WithRouteName("user.get")))
+				return r
+			},
+			path:         "/users/1",
+			expectedBody: `{"Method":"GET","Pattern":"/users/{id}","Name":"user.get"}` + "\n",
+		},
+		{
+			name: "Mounted chi route",
+			mux: func() http.Handler {
+				sub := chi.NewRouter()
+				sub.Get("/users/{id}", HandleTo(testGetRoutedUser))
+				r := chi.NewRouter()
+				r.Mount("/api", sub)
+				return r
+			},
+			path:         "/api/users/1",
+			expectedBody: `{"Method":"GET","Pattern":"/api/users/{id}","Name":""}` + "\n",
+		},
+		{
+			name: "ServeMux route",
+			mux: func() http.Handler {
+				mux := http.NewServeMux()
+				mux.Handle("GET /users/{id}", HandleTo(testGetRoutedUser))
+				return mux
+			},
+			path:         "/users/1",
+			expectedBody: `{"Method":"GET","Pattern":"/users/{id}","Name":""}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+			tc.mux().ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestURLFor(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Get("/orgs/{org}/users/{id:[0-9]+}", testGetOrgUser, WithRouteName("org.user.get"))
+	rt.Get("/users", func() ([]testUser, error) { return nil, nil }, WithRouteName("user.list"))
+
+	testCases := []struct {
+		name        string
+		route       string
+		params      []any
+		expectedURL string
+		expectError bool
+	}{
+		{name: "Fills placeholders", route: "org.user.get", params: []any{"a b", 42}, expectedURL: "/orgs/a%20b/users/42"},
+		{name: "No placeholders", route: "user.list", expectedURL: "/users"},
+		{name: "Unknown name", route: "user.delete", expectError: true},
+		{name: "Missing param", route: "org.user.get", params: []any{"acme"}, expectError: true},
+		{name: "Param not matching constraint", route: "org.user.get", params: []any{"acme", "me"}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, err := URLFor(tc.route, tc.params...)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", url)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if url != tc.expectedURL {
+				t.Errorf("Expected URL %q, got %q", tc.expectedURL, url)
+			}
+		})
+	}
+
+	if routes := rt.Routes(); routes[0].Name != "org.user.get" {
+		t.Errorf("Expected route name %q, got %q", "org.user.get", routes[0].Name)
+	}
+
+	if err := nameRoute("user.list", "/people"); err == nil {
+		t.Error("Expected an error reusing a route name for another pattern")
+	}
+}
@@ -0,0 +1,101 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// errNoRouteContext is reported when a handler takes path params but the
+// request was not routed by chi or by a pattern of http.ServeMux.
+var errNoRouteContext = errors.New("request has no route context to bind path params from")
+
+// RequestWithParams returns a copy of r carrying the given path params as
+// key, value pairs, in route order, as if r had been routed by chi. It lets
+// tests call HandleTo handlers directly:
+//
+//	req := bodyrest.RequestWithParams(httptest.NewRequest("GET", "/users/42", nil), "id", "42")
+//	getUser.ServeHTTP(w, req)
+func RequestWithParams(r *http.Request, keyvals ...string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		rctx.URLParams.Add(keyvals[i], keyvals[i+1])
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+// ErrEncodedSlash is returned for path params containing an encoded slash
+// when SetRejectEncodedSlashes is enabled.
+var ErrEncodedSlash = errors.New("path param contains an encoded slash")
+
+var rejectEncodedSlashes bool
+
+// SetRejectEncodedSlashes makes path params containing %2F fail binding with
+// 400 instead of being decoded to "/".
+func SetRejectEncodedSlashes(reject bool) {
+	rejectEncodedSlashes = reject
+}
+
+// decodePathParam decodes a percent-encoded path param of r as matched by
+// chi, which routes on the escaped path when the URL has one, i.e. when
+// RawPath is set, and on the already decoded path otherwise.
+func decodePathParam(r *http.Request, value string) (string, error) {
+	if r.URL.RawPath == "" || !strings.Contains(value, "%") {
+		return value, nil
+	}
+
+	if rejectEncodedSlashes && strings.Contains(strings.ToUpper(value), "%2F") {
+		return "", ErrEncodedSlash
+	}
+
+	return url.PathUnescape(value)
+}
+
+// routePathParam returns the n-th path param of r, decoded, from chi's route
+// context or, for requests routed by http.ServeMux, from the matched pattern.
+func routePathParam(r *http.Request, n int) (string, bool, error) {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		value, ok := pathParam(rctx, n)
+		if !ok {
+			return "", false, nil
+		}
+
+		value, err := decodePathParam(r, value)
+		return value, err == nil, err
+	}
+
+	if r.Pattern == "" {
+		return "", false, errNoRouteContext
+	}
+
+	_, pattern, _ := strings.Cut(r.Pattern, "/")
+	for _, segment := range strings.Split(pattern, "/") {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok || segment == "{$}" {
+			continue
+		}
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "}"), "...")
+
+		if n == 0 {
+			return r.PathValue(name), true, nil
+		}
+		n--
+	}
+
+	return "", false, nil
+}
+
+// urlParam returns the decoded path param called name, from chi's route
+// context or from http.ServeMux.
+func urlParam(r *http.Request, name string) (string, error) {
+	if chi.RouteContext(r.Context()) != nil {
+		return decodePathParam(r, chi.URLParam(r, name))
+	}
+
+	return r.PathValue(name), nil
+}
@@ -0,0 +1,145 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testGetOrgUser(org string, id int) (map[string]any, error) {
+	return map[string]any{"org": org, "id": id}, nil
+}
+
+func TestHandleToWithoutChi(t *testing.T) {
+	expectedBody := `{"id":42,"org":"acme"}` + "\n"
+
+	testCases := []struct {
+		name         string
+		serve        func(w http.ResponseWriter)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "http.ServeMux",
+			serve: func(w http.ResponseWriter) {
+				mux := http.NewServeMux()
+				mux.Handle("GET /orgs/{org}/users/{id}", HandleTo(testGetOrgUser))
+				mux.ServeHTTP(w, httptest.NewRequest("GET", "/orgs/acme/users/42", nil))
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: expectedBody,
+		},
+		{
+			name: "request with params",
+			serve: func(w http.ResponseWriter) {
+				req := RequestWithParams(httptest.NewRequest("GET", "/orgs/acme/users/42", nil), "org", "acme", "id", "42")
+				HandleTo(testGetOrgUser).ServeHTTP(w, req)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: expectedBody,
+		},
+		{
+			name: "tagged fields with http.ServeMux",
+			serve: func(w http.ResponseWriter) {
+				mux := http.NewServeMux()
+				mux.Handle("GET /orgs/{org}", HandleTo(func(req struct {
+					Org string `path:"org"`
+				}) (map[string]any, error) {
+					return map[string]any{"org": req.Org, "id": 42}, nil
+				}))
+				mux.ServeHTTP(w, httptest.NewRequest("GET", "/orgs/acme", nil))
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: expectedBody,
+		},
+		{
+			name: "no route context",
+			serve: func(w http.ResponseWriter) {
+				HandleTo(testGetOrgUser).ServeHTTP(w, httptest.NewRequest("GET", "/orgs/acme/users/42", nil))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.serve(w)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if tc.expectedBody != "" && w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPathParamDecoding(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		rejectSlashes bool
+		expectedCode  int
+		expectedBody  string
+	}{
+		{
+			name:         "encoded space",
+			path:         "/files/annual%20report",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"name":"annual report","tagged":"annual report"}` + "\n",
+		},
+		{
+			name:         "encoded slash",
+			path:         "/files/a%2Fb",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"name":"a/b","tagged":"a/b"}` + "\n",
+		},
+		{
+			name:         "encoded percent not decoded twice",
+			path:         "/files/%2541",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"name":"%41","tagged":"%41"}` + "\n",
+		},
+		{
+			name:         "trailing encoded percent",
+			path:         "/files/100%25",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"name":"100%","tagged":"100%"}` + "\n",
+		},
+		{
+			name:          "encoded slash rejected",
+			path:          "/files/a%2Fb",
+			rejectSlashes: true,
+			expectedCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetRejectEncodedSlashes(tc.rejectSlashes)
+			defer SetRejectEncodedSlashes(false)
+
+			r := chi.NewRouter()
+			r.Get("/files/{name}", HandleTo(func(name string, req struct {
+				Name string `path:"name"`
+			}) (map[string]string, error) {
+				return map[string]string{"name": name, "tagged": req.Name}, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			if tc.expectedBody != "" && w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
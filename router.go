@@ -0,0 +1,270 @@
+package bodyrest
+
+import (
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Router registers typed handlers on a chi router and keeps a registry of
+// them, for documentation and tooling built on Routes. It answers OPTIONS
+// requests with the Allow header of each pattern and HEAD requests with the
+// GET handler, unless they are registered explicitly.
+type Router struct {
+	mux chi.Router
+
+	methodNotAllowed bool
+	trailingSlash    TrailingSlashPolicy
+
+	mu      sync.RWMutex
+	routes  []RouteInfo
+	methods map[string][]string
+}
+
+// RouterOption configures a Router.
+type RouterOption func(*Router)
+
+// TrailingSlashPolicy decides how a Router treats a path that only matches a
+// route once a trailing slash is added or removed.
+type TrailingSlashPolicy int
+
+const (
+	// TrailingSlashStrict answers such paths with 404.
+	TrailingSlashStrict TrailingSlashPolicy = iota
+	// TrailingSlashRedirect redirects them to the routed path, with 301 for
+	// GET and HEAD and 308 for other methods so the body is sent again.
+	TrailingSlashRedirect
+)
+
+// WithMethodNotAllowed sets whether a path routed for other methods only is
+// answered with 405 and an Allow header, the default, or with 404.
+func WithMethodNotAllowed(enabled bool) RouterOption {
+	return func(rt *Router) {
+		rt.methodNotAllowed = enabled
+	}
+}
+
+// WithTrailingSlash sets the trailing slash policy, TrailingSlashStrict by
+// default.
+func WithTrailingSlash(policy TrailingSlashPolicy) RouterOption {
+	return func(rt *Router) {
+		rt.trailingSlash = policy
+	}
+}
+
+// RouteInfo describes a route registered through a Router.
+type RouteInfo struct {
+	Method      string
+	Pattern     string
+	Name        string
+	HandlerType reflect.Type
+	Examples    []Example
+}
+
+// NewRouter returns a Router registering routes on mux, or on a new chi
+// router if mux is nil. Unmatched requests are answered through the rest
+// error handler, like the errors of the typed handlers.
+func NewRouter(mux chi.Router, opts ...RouterOption) *Router {
+	if mux == nil {
+		mux = chi.NewRouter()
+	}
+
+	rt := &Router{mux: mux, methodNotAllowed: true, methods: map[string][]string{}}
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	mux.NotFound(headHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, http.StatusNotFound, nil)
+	})))
+	mux.MethodNotAllowed(headHandler(http.HandlerFunc(rt.notAllowedHandler)))
+
+	return rt
+}
+
+// Handle registers handlerFunc, wrapped by HandleTo, for method and pattern.
+// When the handler binds path params through path-tagged struct fields, every
+// placeholder of pattern must be bound, or registration fails, as it does for
+// ambiguous positional path params, see WithPathParams.
+func (rt *Router) Handle(method, pattern string, handlerFunc interface{}, opts ...Option) {
+	cfg := newRouteConfig(opts)
+	if err := checkPathParams(pattern, reflect.TypeOf(handlerFunc)); err != nil {
+		log.Fatal(err)
+	}
+	if err := checkPathBinding(pattern, reflect.TypeOf(handlerFunc), cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.routeName != "" {
+		if err := nameRoute(cfg.routeName, pattern); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	rt.register(method, pattern, HandleTo(handlerFunc, opts...), RouteInfo{
+		Method:      method,
+		Pattern:     pattern,
+		Name:        cfg.routeName,
+		HandlerType: reflect.TypeOf(handlerFunc),
+		Examples:    cfg.examples,
+	})
+}
+
+// register adds handler to the mux and route to the registry, along with the
+// automatic OPTIONS and HEAD handlers.
+func (rt *Router) register(method, pattern string, handler http.Handler, route RouteInfo) {
+	rt.mux.Method(method, pattern, handler)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	registered := rt.methods[pattern]
+	if len(registered) == 0 && method != http.MethodOptions {
+		rt.mux.Method(http.MethodOptions, pattern, rt.optionsHandler(pattern))
+	}
+	if method == http.MethodGet && !slices.Contains(registered, http.MethodHead) {
+		rt.mux.Method(http.MethodHead, pattern, headHandler(handler))
+	}
+	rt.methods[pattern] = append(registered, method)
+
+	rt.routes = append(rt.routes, route)
+}
+
+func (rt *Router) Get(pattern string, handlerFunc interface{}, opts ...Option) {
+	rt.Handle(http.MethodGet, pattern, handlerFunc, opts...)
+}
+
+func (rt *Router) Post(pattern string, handlerFunc interface{}, opts ...Option) {
+	rt.Handle(http.MethodPost, pattern, handlerFunc, opts...)
+}
+
+func (rt *Router) Put(pattern string, handlerFunc interface{}, opts ...Option) {
+	rt.Handle(http.MethodPut, pattern, handlerFunc, opts...)
+}
+
+func (rt *Router) Patch(pattern string, handlerFunc interface{}, opts ...Option) {
+	rt.Handle(http.MethodPatch, pattern, handlerFunc, opts...)
+}
+
+func (rt *Router) Delete(pattern string, handlerFunc interface{}, opts ...Option) {
+	rt.Handle(http.MethodDelete, pattern, handlerFunc, opts...)
+}
+
+// Routes returns the registered routes in registration order.
+func (rt *Router) Routes() []RouteInfo {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	return append([]RouteInfo(nil), rt.routes...)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rt.trailingSlash == TrailingSlashRedirect {
+		if target, ok := rt.slashRedirect(r); ok {
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+
+			http.Redirect(w, r, target, status)
+			return
+		}
+	}
+
+	rt.mux.ServeHTTP(w, r)
+}
+
+// slashRedirect returns the URL to redirect r to when its path is not routed
+// but the path with the trailing slash added or removed is.
+func (rt *Router) slashRedirect(r *http.Request) (string, bool) {
+	path := r.URL.Path
+	if path == "/" || rt.matches(r.Method, path) {
+		return "", false
+	}
+
+	alt := path + "/"
+	if strings.HasSuffix(path, "/") {
+		alt = strings.TrimRight(path, "/")
+	}
+	if alt == "" || !rt.matches(r.Method, alt) {
+		return "", false
+	}
+
+	target := *r.URL
+	target.Path = alt
+	target.RawPath = ""
+	return target.RequestURI(), true
+}
+
+func (rt *Router) matches(method, path string) bool {
+	return rt.mux.Match(chi.NewRouteContext(), method, path)
+}
+
+// routingMethods are the methods probed to build the Allow header of a 405.
+var routingMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+func (rt *Router) notAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	if !rt.methodNotAllowed {
+		writeError(w, r, http.StatusNotFound, nil)
+		return
+	}
+
+	var allowed []string
+	for _, method := range routingMethods {
+		if rt.matches(method, r.URL.Path) {
+			allowed = append(allowed, method)
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, r, http.StatusMethodNotAllowed, nil)
+}
+
+// allowedMethods returns the methods served for pattern, including the
+// automatic HEAD and OPTIONS.
+func (rt *Router) allowedMethods(pattern string) []string {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	allowed := append([]string(nil), rt.methods[pattern]...)
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+
+	return allowed
+}
+
+func (rt *Router) optionsHandler(pattern string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(rt.allowedMethods(pattern), ", "))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// headHandler runs h discarding the body for HEAD requests.
+func headHandler(h http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w = headResponseWriter{w}
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
@@ -0,0 +1,132 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Post("/users", testCreateUser, WithExample(testUser{Name: "John"}, testUser{Name: "John"}))
+	rt.Get("/orgs/{org}/users/{id}", testGetOrgUser)
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"John"}`))
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	routes := rt.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(routes))
+	}
+
+	expected := RouteInfo{
+		Method:      http.MethodPost,
+		Pattern:     "/users",
+		HandlerType: reflect.TypeOf(testCreateUser),
+		Examples:    []Example{{Request: testUser{Name: "John"}, Response: testUser{Name: "John"}}},
+	}
+	if !reflect.DeepEqual(routes[0], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, routes[0])
+	}
+
+	if routes[1].Method != http.MethodGet || routes[1].Pattern != "/orgs/{org}/users/{id}" || routes[1].Examples != nil {
+		t.Errorf("Unexpected route %+v", routes[1])
+	}
+}
+
+func TestRouterHeadAndOptions(t *testing.T) {
+	rt := NewRouter(nil)
+	rt.Get("/orgs/{org}/users/{id}", testGetOrgUser)
+	rt.Delete("/orgs/{org}/users/{id}", testGetOrgUser)
+	rt.Post("/users", testCreateUser)
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedAllow  string
+		expectEmpty    bool
+	}{
+		{name: "options for get route", method: http.MethodOptions, path: "/orgs/acme/users/1", expectedStatus: http.StatusNoContent, expectedAllow: "GET, DELETE, HEAD, OPTIONS", expectEmpty: true},
+		{name: "options for post route", method: http.MethodOptions, path: "/users", expectedStatus: http.StatusNoContent, expectedAllow: "POST, OPTIONS", expectEmpty: true},
+		{name: "head runs get handler", method: http.MethodHead, path: "/orgs/acme/users/1", expectedStatus: http.StatusOK, expectEmpty: true},
+		{name: "head keeps get errors", method: http.MethodHead, path: "/orgs/acme/users/abc", expectedStatus: http.StatusBadRequest, expectEmpty: true},
+		{name: "no head without get", method: http.MethodHead, path: "/users", expectedStatus: http.StatusMethodNotAllowed, expectEmpty: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if allow := w.Header().Get("Allow"); tc.expectedAllow != "" && allow != tc.expectedAllow {
+				t.Errorf("Expected Allow %q, got %q", tc.expectedAllow, allow)
+			}
+
+			if tc.expectEmpty && w.Body.Len() != 0 {
+				t.Errorf("Expected empty body, got %q", w.Body.String())
+			}
+		})
+	}
+
+	if len(rt.Routes()) != 3 {
+		t.Errorf("Expected 3 routes, got %d", len(rt.Routes()))
+	}
+}
+
+func TestRouterRoutingErrors(t *testing.T) {
+	testCases := []struct {
+		name             string
+		opts             []RouterOption
+		method           string
+		path             string
+		expectedStatus   int
+		expectedLocation string
+		expectedAllow    string
+	}{
+		{name: "method not allowed", method: http.MethodPut, path: "/users", expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "POST, OPTIONS"},
+		{name: "method not allowed as not found", opts: []RouterOption{WithMethodNotAllowed(false)}, method: http.MethodPut, path: "/users", expectedStatus: http.StatusNotFound},
+		{name: "not found", method: http.MethodGet, path: "/missing", expectedStatus: http.StatusNotFound},
+		{name: "strict trailing slash", method: http.MethodGet, path: "/orgs/acme/users/1/", expectedStatus: http.StatusNotFound},
+		{name: "redirect get without slash", opts: []RouterOption{WithTrailingSlash(TrailingSlashRedirect)}, method: http.MethodGet, path: "/orgs/acme/users/1/?fields=name", expectedStatus: http.StatusMovedPermanently, expectedLocation: "/orgs/acme/users/1?fields=name"},
+		{name: "redirect post with slash", opts: []RouterOption{WithTrailingSlash(TrailingSlashRedirect)}, method: http.MethodPost, path: "/users/", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/users"},
+		{name: "no redirect when unrouted", opts: []RouterOption{WithTrailingSlash(TrailingSlashRedirect)}, method: http.MethodGet, path: "/missing/", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRouter(nil, tc.opts...)
+			rt.Post("/users", testCreateUser)
+			rt.Get("/orgs/{org}/users/{id}", testGetOrgUser)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if location := w.Header().Get("Location"); location != tc.expectedLocation {
+				t.Errorf("Expected Location %q, got %q", tc.expectedLocation, location)
+			}
+
+			if allow := w.Header().Get("Allow"); allow != tc.expectedAllow {
+				t.Errorf("Expected Allow %q, got %q", tc.expectedAllow, allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RoleHolder is implemented by principal types, e.g. the claims type given
+// to RegisterClaims, whose roles decide which scope tagged response fields
+// are encoded.
+type RoleHolder interface {
+	Roles() []string
+}
+
+type rolesContextKey struct{}
+
+// withPrincipalRoles returns r carrying the roles of the bound principal, if
+// it has any.
+func withPrincipalRoles(r *http.Request, plan *bindPlan, args []reflect.Value) *http.Request {
+	principal, _, _ := boundRoles(plan, args)
+	holder, ok := principal.(RoleHolder)
+	if !ok {
+		return r
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), rolesContextKey{}, holder.Roles()))
+}
+
+// responseShape selects the struct fields encoded in a response. Fields
+// tagged scope:"admin,support" are only encoded for principals with one of
+// the listed roles, fields tagged view:"summary,detail" only in the listed
+// views when a view is set.
+type responseShape struct {
+	roles []string
+	view  string
+}
+
+func responseShapeOf(r *http.Request, cfg *routeConfig) *responseShape {
+	roles, _ := r.Context().Value(rolesContextKey{}).([]string)
+	view, _ := requestedView(r, cfg)
+	return &responseShape{roles: roles, view: view}
+}
+
+type shapeContextKey struct{}
+
+// withResponseShape returns r carrying the response shape of the route, for
+// handlers returned by the route handler, such as StreamJSON, to encode with.
+func withResponseShape(r *http.Request, cfg *routeConfig) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), shapeContextKey{}, responseShapeOf(r, cfg)))
+}
+
+// requestShape returns the response shape carried by r, or the shape of the
+// principal roles of r when there is none.
+func requestShape(r *http.Request) *responseShape {
+	if shape, ok := r.Context().Value(shapeContextKey{}).(*responseShape); ok {
+		return shape
+	}
+
+	roles, _ := r.Context().Value(rolesContextKey{}).([]string)
+	return &responseShape{roles: roles}
+}
+
+// appliesTo reports whether values of t have fields shape may exclude. Types
+// holding interface values are always walked, since the scope of their
+// dynamic values is only known per value.
+func (s *responseShape) appliesTo(t reflect.Type) bool {
+	return s != nil && t != nil && (typeHasTag(t, "scope") || s.view != "" && typeHasTag(t, "view") || typeHasLinks(t) || typeHasInterface(t))
+}
+
+func (s *responseShape) includes(field reflect.StructField) bool {
+	if s == nil {
+		return true
+	}
+
+	if scope, ok := field.Tag.Lookup("scope"); ok && !listsAny(scope, s.roles...) {
+		return false
+	}
+
+	if view, ok := field.Tag.Lookup("view"); ok && s.view != "" && !listsAny(view, s.view) {
+		return false
+	}
+
+	return true
+}
+
+// listsAny reports whether the comma separated list contains any of values.
+func listsAny(list string, values ...string) bool {
+	for _, item := range strings.Split(list, ",") {
+		if slices.Contains(values, strings.TrimSpace(item)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type typeTag struct {
+	t   reflect.Type
+	tag string
+}
+
+var typeTagCache sync.Map
+
+// typeHasTag reports whether t, or a type it contains, has a struct field
+// with the given tag.
+func typeHasTag(t reflect.Type, tag string) bool {
+	key := typeTag{t: t, tag: tag}
+	if has, ok := typeTagCache.Load(key); ok {
+		return has.(bool)
+	}
+
+	has := searchType(t, func(t reflect.Type) bool {
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if _, ok := t.Field(i).Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+		return false
+	}, map[reflect.Type]bool{})
+	typeTagCache.Store(key, has)
+	return has
+}
+
+var interfaceTypeCache sync.Map
+
+// typeHasInterface reports whether t is, or contains, an interface type.
+func typeHasInterface(t reflect.Type) bool {
+	if has, ok := interfaceTypeCache.Load(t); ok {
+		return has.(bool)
+	}
+
+	has := searchType(t, func(t reflect.Type) bool {
+		return t.Kind() == reflect.Interface
+	}, map[reflect.Type]bool{})
+	interfaceTypeCache.Store(t, has)
+	return has
+}
+
+// searchType reports whether match holds for t or a type it contains.
+func searchType(t reflect.Type, match func(t reflect.Type) bool, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	if match(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return searchType(t.Elem(), match, seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if searchType(t.Field(i).Type, match, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,149 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type testStaffClaims struct {
+	roles []string
+}
+
+func (c testStaffClaims) Roles() []string {
+	return c.roles
+}
+
+type testAccount struct {
+	Name    string        `json:"name"`
+	Email   string        `json:"email" scope:"admin,support"`
+	Balance int           `json:"balance" scope:"admin"`
+	Owner   *testAccount  `json:"owner,omitempty"`
+	Members []testAccount `json:"members,omitempty"`
+}
+
+func TestScopeTags(t *testing.T) {
+	RegisterClaims(func(ctx context.Context, token string) (testStaffClaims, error) {
+		if token == "anonymous" {
+			return testStaffClaims{}, nil
+		}
+		return testStaffClaims{roles: []string{token}}, nil
+	})
+
+	account := testAccount{
+		Name: "acme", Email: "ops@acme.test", Balance: 100,
+		Members: []testAccount{{Name: "john", Email: "john@acme.test", Balance: 5}},
+	}
+	handler := HandleTo(func(claims testStaffClaims) (testAccount, error) {
+		return account, nil
+	})
+	public := HandleTo(func() (testAccount, error) {
+		return account, nil
+	})
+
+	testCases := []struct {
+		name         string
+		handler      http.Handler
+		token        string
+		expectedBody string
+	}{
+		{name: "admin", handler: handler, token: "admin", expectedBody: `{"name":"acme","email":"ops@acme.test","balance":100,"members":[{"name":"john","email":"john@acme.test","balance":5}]}`},
+		{name: "support", handler: handler, token: "support", expectedBody: `{"name":"acme","email":"ops@acme.test","members":[{"name":"john","email":"john@acme.test"}]}`},
+		{name: "no roles", handler: handler, token: "anonymous", expectedBody: `{"name":"acme","members":[{"name":"john"}]}`},
+		{name: "no principal", handler: public, expectedBody: `{"name":"acme","members":[{"name":"john"}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/account", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+			tc.handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if strings.Join(strings.Fields(w.Body.String()), "") != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type testAccountRef struct {
+	Name string `json:"name"`
+}
+
+func TestScopeDynamicValues(t *testing.T) {
+	RegisterClaims(func(ctx context.Context, token string) (testStaffClaims, error) {
+		return testStaffClaims{roles: []string{token}}, nil
+	})
+
+	account := testAccount{Name: "acme", Email: "ops@acme.test", Balance: 100}
+
+	testCases := []struct {
+		name    string
+		handler http.Handler
+		accept  string
+		body    string
+	}{
+		{
+			name: "any field",
+			handler: HandleTo(func(claims testStaffClaims) (struct{ Data any }, error) {
+				return struct{ Data any }{Data: account}, nil
+			}),
+		},
+		{
+			name: "map with int keys",
+			handler: HandleTo(func(claims testStaffClaims) (map[int]testAccount, error) {
+				return map[int]testAccount{1: account}, nil
+			}),
+		},
+		{
+			name: "bulk results",
+			handler: HandleBulk(func(claims testStaffClaims, ref testAccountRef) (testAccount, error) {
+				return account, nil
+			}),
+			body: `[{"name":"acme"}]`,
+		},
+		{
+			name: "stream",
+			handler: HandleTo(func(claims testStaffClaims) http.Handler {
+				return StreamJSON(slices.Values([]testAccount{account}))
+			}),
+			accept: ndjsonContentType,
+		},
+		{
+			name: "server-sent events",
+			handler: HandleSSE(func(claims testStaffClaims, sink EventSink) error {
+				return sink.Send("account", account)
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := "GET"
+			if tc.body != "" {
+				method = "POST"
+			}
+			req := httptest.NewRequest(method, "/accounts", strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer support")
+			req.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+			tc.handler.ServeHTTP(w, req)
+
+			if w.Code >= http.StatusBadRequest {
+				t.Fatalf("Expected a successful status code, got %d", w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), `"email":"ops@acme.test"`) || strings.Contains(w.Body.String(), `"balance"`) {
+				t.Errorf("Expected email without balance, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package bodyrest
+
+import "net/http"
+
+// SignatureVerifierFunc checks the signature of a raw request body, e.g. the
+// HMAC of a webhook delivery, against the request headers.
+type SignatureVerifierFunc func(body []byte, header http.Header) error
+
+// WithSignatureVerifier verifies the raw request body before anything is
+// decoded. Requests failing verification are rejected with 401; verified
+// bodies are bound as usual.
+func WithSignatureVerifier(verify SignatureVerifierFunc) Option {
+	return func(cfg *routeConfig) {
+		cfg.signatureVerifier = verify
+	}
+}
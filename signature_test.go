@@ -0,0 +1,62 @@
+package bodyrest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testHMACVerifier(secret string) SignatureVerifierFunc {
+	return func(body []byte, header http.Header) error {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(expected), []byte(header.Get("X-Signature"))) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	}
+}
+
+func TestWithSignatureVerifier(t *testing.T) {
+	payload := `{"name":"john"}`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	testCases := []struct {
+		name           string
+		signature      string
+		expectedStatus int
+	}{
+		{name: "Valid signature", signature: signature, expectedStatus: http.StatusOK},
+		{name: "Invalid signature", signature: "deadbeef", expectedStatus: http.StatusUnauthorized},
+		{name: "Missing signature", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/hooks", bytes.NewBufferString(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Signature", tc.signature)
+
+			r := chi.NewRouter()
+			r.Post("/hooks", HandleTo(testImportUser, WithSignatureVerifier(testHMACVerifier("secret"))))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
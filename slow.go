@@ -0,0 +1,76 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SlowRequest describes a handler call that exceeded the slow request
+// threshold.
+type SlowRequest struct {
+	Method string
+	// Route is the matched route pattern, or the path when there is none.
+	Route string
+	// Params summarizes the bound params: the values of path params and the
+	// types of the others.
+	Params   []string
+	Duration time.Duration
+}
+
+// SlowRequestHook is called with the handler calls exceeding the slow
+// request threshold.
+type SlowRequestHook func(ctx context.Context, slow SlowRequest)
+
+var slowRequests struct {
+	threshold time.Duration
+	hook      SlowRequestHook
+}
+
+// SetSlowRequestHook calls hook once a handler of HandleTo returns after
+// more than threshold, e.g. to alert on specific slow resources rather than
+// aggregate latency. The handler call is timed up to the end of its
+// response, including the work of an http.Handler it returns, but not
+// binding. A nil hook disables detection.
+func SetSlowRequestHook(threshold time.Duration, hook SlowRequestHook) {
+	slowRequests.threshold = threshold
+	slowRequests.hook = hook
+}
+
+// WithSlowThreshold overrides the threshold of SetSlowRequestHook for the
+// route, e.g. for report exports expected to take longer.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(cfg *routeConfig) {
+		cfg.slowThreshold = d
+	}
+}
+
+// detectSlow times the calls of next until the response is written and
+// reports those exceeding the threshold to the slow request hook.
+func detectSlow(cfg *routeConfig, plan *bindPlan) func(next Invoker) Invoker {
+	return func(next Invoker) Invoker {
+		return func(r *http.Request, args []any) ([]any, error) {
+			hook, threshold := slowRequests.hook, slowRequests.threshold
+			if hook == nil {
+				return next(r, args)
+			}
+			if cfg.slowThreshold > 0 {
+				threshold = cfg.slowThreshold
+			}
+
+			start := time.Now()
+			afterResponse(r, func() {
+				if elapsed := time.Since(start); elapsed > threshold {
+					hook(r.Context(), SlowRequest{
+						Method:   r.Method,
+						Route:    routePattern(r),
+						Params:   paramSummary(plan, args),
+						Duration: elapsed,
+					})
+				}
+			})
+
+			return next(r, args)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSetSlowRequestHook(t *testing.T) {
+	var reported []SlowRequest
+	SetSlowRequestHook(20*time.Millisecond, func(ctx context.Context, slow SlowRequest) {
+		reported = append(reported, slow)
+	})
+	defer SetSlowRequestHook(0, nil)
+
+	handler := func(id int, delay string) (string, error) {
+		d, _ := time.ParseDuration(delay)
+		time.Sleep(d)
+		return "done", nil
+	}
+
+	r := chi.NewRouter()
+	r.Get("/reports/{id}/{delay}", HandleTo(handler))
+	r.Get("/exports/{id}/{delay}", HandleTo(handler, WithSlowThreshold(time.Second)))
+	r.Get("/downloads/{id}/{delay}", HandleTo(func(id int, delay string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			handler(id, delay)
+		}
+	}))
+
+	testCases := []struct {
+		name             string
+		path             string
+		expectedReported bool
+		expectedRoute    string
+	}{
+		{name: "fast", path: "/reports/1/0s", expectedReported: false},
+		{name: "slow", path: "/reports/2/30ms", expectedReported: true, expectedRoute: "/reports/{id}/{delay}"},
+		{name: "below route threshold", path: "/exports/3/30ms", expectedReported: false},
+		{name: "slow returned handler", path: "/downloads/2/30ms", expectedReported: true, expectedRoute: "/downloads/{id}/{delay}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reported = nil
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+			if (len(reported) == 1) != tc.expectedReported {
+				t.Fatalf("Expected reported %v, got %+v", tc.expectedReported, reported)
+			}
+			if !tc.expectedReported {
+				return
+			}
+
+			slow := reported[0]
+			if slow.Route != tc.expectedRoute || slow.Duration < 20*time.Millisecond {
+				t.Errorf("Expected slow call of %s, got %+v", tc.expectedRoute, slow)
+			}
+			if !reflect.DeepEqual(slow.Params, []string{"2", "30ms"}) {
+				t.Errorf("Expected params [2 30ms], got %v", slow.Params)
+			}
+		})
+	}
+}
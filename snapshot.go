@@ -0,0 +1,116 @@
+package bodyrest
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// SnapshotArgs captures the bound arguments of a call so a wrapper can
+// invoke the handler again without binding the request, whose body is
+// already consumed. Each call of the returned function gives a fresh deep
+// copy of the bound structs, maps and slices, so changes made by a failed
+// attempt do not leak into the next one. Other arguments, such as injected
+// and provided pointers, are shared between the copies, as are unexported
+// struct fields.
+func SnapshotArgs(args []any) func() []any {
+	snapshot := copyArgs(args)
+	return func() []any {
+		return copyArgs(snapshot)
+	}
+}
+
+// WithRetry re-invokes the handler with a snapshot of its bound arguments
+// while it returns an error retryable reports as transient, up to attempts
+// calls in total. It is meant for idempotent handlers. Provided params, e.g.
+// transactions, are begun once around all the attempts.
+func WithRetry(attempts int, retryable func(err error) bool) Option {
+	return WithWrapper(func(next Invoker) Invoker {
+		return func(r *http.Request, args []any) ([]any, error) {
+			snapshot := SnapshotArgs(args)
+
+			results, err := next(r, snapshot())
+			for attempt := 1; attempt < attempts && err != nil && retryable(err); attempt++ {
+				if r.Context().Err() != nil {
+					break
+				}
+				results, err = next(r, snapshot())
+			}
+
+			return results, err
+		}
+	})
+}
+
+func copyArgs(args []any) []any {
+	copied := make([]any, len(args))
+	for i, arg := range args {
+		copied[i] = arg
+		if arg == nil {
+			continue
+		}
+
+		switch v := reflect.ValueOf(arg); v.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+			copied[i] = deepCopy(v).Interface()
+		}
+	}
+
+	return copied
+}
+
+// deepCopy returns a copy of v sharing no exported pointers, maps or slices
+// with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	}
+
+	return v
+}
@@ -0,0 +1,86 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errTestTransient = errors.New("transient")
+
+type testOrderLines struct {
+	Lines []string          `json:"lines"`
+	Meta  map[string]string `json:"meta"`
+}
+
+func TestSnapshotArgs(t *testing.T) {
+	original := testOrderLines{Lines: []string{"a"}, Meta: map[string]string{"k": "v"}}
+	snapshot := SnapshotArgs([]any{original, 7})
+
+	first := snapshot()
+	first[0].(testOrderLines).Lines[0] = "changed"
+	first[0].(testOrderLines).Meta["k"] = "changed"
+
+	second := snapshot()
+	if !reflect.DeepEqual(second, []any{original, 7}) {
+		t.Errorf("Expected %v, got %v", []any{original, 7}, second)
+	}
+	if original.Lines[0] != "a" || original.Meta["k"] != "v" {
+		t.Errorf("Expected the original arguments untouched, got %v", original)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	testCases := []struct {
+		name             string
+		failures         int
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{name: "Succeeds first time", failures: 0, expectedStatus: http.StatusOK, expectedAttempts: 1},
+		{name: "Succeeds on retry", failures: 2, expectedStatus: http.StatusOK, expectedAttempts: 3},
+		{name: "Gives up", failures: 5, expectedStatus: http.StatusInternalServerError, expectedAttempts: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			handler := func(order testOrderLines) (testOrderLines, error) {
+				attempts++
+				order.Lines = append(order.Lines[:1], "attempt")
+				order.Meta["attempts"] = "seen"
+				if attempts <= tc.failures {
+					return testOrderLines{}, errTestTransient
+				}
+				return order, nil
+			}
+
+			r := chi.NewRouter()
+			r.Post("/orders", HandleTo(handler, WithRetry(3, func(err error) bool {
+				return errors.Is(err, errTestTransient)
+			})))
+
+			req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"lines":["a","b"],"meta":{"k":"v"}}`))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if attempts != tc.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tc.expectedAttempts, attempts)
+			}
+
+			expectedBody := `{"lines":["a","attempt"],"meta":{"attempts":"seen","k":"v"}}` + "\n"
+			if tc.expectedStatus == http.StatusOK && w.Body.String() != expectedBody {
+				t.Errorf("Expected body %q, got %q", expectedBody, w.Body.String())
+			}
+		})
+	}
+}
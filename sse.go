@@ -0,0 +1,203 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventSink writes Server-Sent Events to the client of a HandleSSE handler.
+type EventSink interface {
+	// Send writes an event. Strings are sent as is, other values are
+	// encoded as JSON, shaped by the scope and view of the route. An empty event name sends an unnamed message.
+	Send(event string, data any) error
+	// Context is canceled when the client disconnects or Drain is called.
+	Context() context.Context
+}
+
+var (
+	sseHeartbeatInterval = 15 * time.Second
+	eventSinkType        = reflect.TypeOf((*EventSink)(nil)).Elem()
+)
+
+// SetSSEHeartbeat sets how often HandleSSE writes keep-alive comments to idle
+// streams. A non-positive interval disables heartbeats.
+func SetSSEHeartbeat(interval time.Duration) {
+	sseHeartbeatInterval = interval
+}
+
+// HandleSSE binds the request like HandleTo and then streams events to the
+// client. The handler must take bodyrest.EventSink as its last parameter and
+// return an error, e.g. func(req T, stream bodyrest.EventSink) error.
+// Errors returned before the first event go through the rest error handler.
+func HandleSSE(handlerFunc interface{}, opts ...Option) http.HandlerFunc {
+	handlerType := reflect.TypeOf(handlerFunc)
+	if handlerType.Kind() != reflect.Func {
+		log.Fatal("Handler is not a function")
+	}
+
+	if handlerType.NumIn() == 0 || handlerType.In(handlerType.NumIn()-1) != eventSinkType {
+		log.Fatal("SSE handler must take bodyrest.EventSink as its last parameter")
+	}
+
+	if handlerType.NumOut() != 1 || handlerType.Out(0) != errorType {
+		log.Fatal("SSE handler must return exactly one error value")
+	}
+
+	cfg := newRouteConfig(opts)
+	plan := newBindPlan(handlerType, handlerType.NumIn()-1)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer recoverHandler(w, r)
+
+		finish, ok := admitRequest(w, r)
+		if !ok {
+			return
+		}
+		defer finish()
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			log.Println("response writer does not support flushing")
+			writeError(w, r, http.StatusInternalServerError, nil)
+			return
+		}
+
+		ctx, stop := withDrainSignal(r.Context())
+		defer stop()
+		r = r.WithContext(ctx)
+
+		handlerArgsToCall, ok := bindArgs(w, r, cfg, plan)
+		if !ok {
+			return
+		}
+		defer releaseArgs(handlerArgsToCall)
+
+		r = withPrincipalRoles(r, plan, handlerArgsToCall)
+		sink := &eventSink{w: w, flusher: flusher, ctx: r.Context(), shape: responseShapeOf(r, cfg)}
+
+		done := make(chan struct{})
+		var heartbeats sync.WaitGroup
+		if sseHeartbeatInterval > 0 {
+			heartbeats.Add(1)
+			go func() {
+				defer heartbeats.Done()
+				sink.heartbeat(sseHeartbeatInterval, done)
+			}()
+		}
+
+		handlerArgsToCall = append(handlerArgsToCall, reflect.ValueOf(sink))
+		results := reflect.ValueOf(handlerFunc).Call(handlerArgsToCall)
+
+		close(done)
+		heartbeats.Wait()
+
+		err, _ := results[0].Interface().(error)
+		if err == nil || errors.Is(err, context.Canceled) {
+			return
+		}
+
+		if sink.hasStarted() {
+			log.Printf("event stream ended with error: %v\n", err)
+			return
+		}
+
+		log.Printf("handler returned error: %v\n", err)
+		writeError(w, r, statusFromError(err), err)
+	})
+}
+
+type eventSink struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	ctx     context.Context
+	shape   *responseShape
+	started bool
+}
+
+func (s *eventSink) Context() context.Context {
+	return s.ctx
+}
+
+func (s *eventSink) Send(event string, data any) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		encoded, err := marshalShaped(v, false, s.shape)
+		if err != nil {
+			return fmt.Errorf("failed to encode event data: %w", err)
+		}
+		payload = string(encoded)
+	}
+
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(payload, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+func (s *eventSink) heartbeat(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *eventSink) hasStarted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+func (s *eventSink) write(chunk string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.Header().Set("Connection", "keep-alive")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err := s.w.Write([]byte(chunk)); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,81 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errTestStreamNotFound = errors.New("stream not found")
+
+func testStream(id int, stream EventSink) error {
+	if id == 0 {
+		return errTestStreamNotFound
+	}
+
+	if err := stream.Send("progress", map[string]int{"id": id}); err != nil {
+		return err
+	}
+
+	return stream.Send("", "done")
+}
+
+func TestHandleSSE(t *testing.T) {
+	RegisterError(errTestStreamNotFound, http.StatusNotFound)
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Streams events",
+			path:           "/streams/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "event: progress\ndata: {\"id\":1}\n\ndata: done\n\n",
+		},
+		{
+			name:           "Error before first event",
+			path:           "/streams/0",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"Something went wrong. Please try again later."}` + "\n",
+		},
+		{
+			name:           "Invalid path param",
+			path:           "/streams/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/streams/{id}", HandleSSE(testStream))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+
+			if tc.expectedStatus == http.StatusOK && !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+				t.Errorf("Expected event stream content type, got %s", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package bodyrest
+
+import (
+	"iter"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	streamFlushItems  = 100
+)
+
+type jsonStream[T any] struct {
+	items iter.Seq[T]
+}
+
+// StreamJSON returns a handler that encodes items one at a time as they are
+// produced, flushing periodically so large exports are never buffered. Items
+// are written as a JSON array, or as newline-delimited JSON when the client
+// accepts application/x-ndjson. Items are shaped by the scope and view of
+// the route returning the handler, like other responses. The stream ends
+// early when the client disconnects or Drain is called.
+func StreamJSON[T any](items iter.Seq[T]) http.Handler {
+	return &jsonStream[T]{items: items}
+}
+
+func (s *jsonStream[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, stop := withDrainSignal(r.Context())
+	defer stop()
+	r = r.WithContext(ctx)
+
+	ndjson := strings.Contains(r.Header.Get("Accept"), ndjsonContentType)
+	if ndjson {
+		w.Header().Set("Content-Type", ndjsonContentType)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+
+	if !ndjson {
+		w.Write([]byte("["))
+	}
+
+	shape := requestShape(r)
+	count := 0
+	for item := range s.items {
+		if r.Context().Err() != nil {
+			return
+		}
+
+		if !ndjson && count > 0 {
+			w.Write([]byte(","))
+		}
+
+		encoded, err := marshalShaped(item, false, shape)
+		if err != nil {
+			log.Printf("failed to encode streamed item: %v\n", err)
+			return
+		}
+		w.Write(append(encoded, '\n'))
+
+		count++
+		if flusher != nil && count%streamFlushItems == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if !ndjson {
+		w.Write([]byte("]\n"))
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,63 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testExportUsers() http.Handler {
+	users := []testUser{{Name: "john"}, {Name: "jane"}}
+	return StreamJSON(slices.Values(users))
+}
+
+func TestStreamJSON(t *testing.T) {
+	testCases := []struct {
+		name                string
+		accept              string
+		expectedBody        string
+		expectedContentType string
+	}{
+		{
+			name:                "JSON array",
+			expectedBody:        "[{\"name\":\"john\"}\n,{\"name\":\"jane\"}\n]\n",
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "Newline-delimited JSON",
+			accept:              "application/x-ndjson",
+			expectedBody:        "{\"name\":\"john\"}\n{\"name\":\"jane\"}\n",
+			expectedContentType: "application/x-ndjson",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/users", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept", tc.accept)
+
+			r := chi.NewRouter()
+			r.Get("/users", HandleTo(testExportUsers))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+
+			if w.Header().Get("Content-Type") != tc.expectedContentType {
+				t.Errorf("Expected content type %s, got %s", tc.expectedContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
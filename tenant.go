@@ -0,0 +1,103 @@
+package bodyrest
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Tenant can be taken as a handler parameter to receive the tenant resolved
+// by the resolver set with SetTenantResolver.
+type Tenant string
+
+// ErrNoTenant is returned by resolvers when the request does not identify a
+// tenant. It is mapped to 400.
+var ErrNoTenant = errors.New("no tenant in request")
+
+func init() {
+	RegisterError(ErrNoTenant, http.StatusBadRequest)
+
+	registerInjector(typeOf[Tenant](), func(r *http.Request) (reflect.Value, error) {
+		tenant, err := TenantFromRequest(r)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return reflect.ValueOf(tenant), nil
+	})
+}
+
+// TenantResolver extracts the tenant of a request.
+type TenantResolver interface {
+	ResolveTenant(r *http.Request) (Tenant, error)
+}
+
+// TenantResolverFunc adapts a function to the TenantResolver interface.
+type TenantResolverFunc func(r *http.Request) (Tenant, error)
+
+func (f TenantResolverFunc) ResolveTenant(r *http.Request) (Tenant, error) {
+	return f(r)
+}
+
+var tenantResolver TenantResolver
+
+// SetTenantResolver sets how Tenant parameters are resolved.
+func SetTenantResolver(resolver TenantResolver) {
+	tenantResolver = resolver
+}
+
+// TenantFromRequest resolves the tenant of r with the configured resolver,
+// e.g. for use in gates and other hooks.
+func TenantFromRequest(r *http.Request) (Tenant, error) {
+	if tenantResolver == nil {
+		return "", errors.New("no tenant resolver set")
+	}
+
+	tenant, err := tenantResolver.ResolveTenant(r)
+	if err != nil {
+		return "", err
+	}
+	if tenant == "" {
+		return "", ErrNoTenant
+	}
+
+	return tenant, nil
+}
+
+// TenantFromHeader resolves the tenant from the given request header.
+func TenantFromHeader(name string) TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (Tenant, error) {
+		return Tenant(strings.TrimSpace(r.Header.Get(name))), nil
+	})
+}
+
+// TenantFromSubdomain resolves the tenant from the label in front of
+// baseDomain, so acme.example.com gives "acme" for baseDomain example.com.
+func TenantFromSubdomain(baseDomain string) TenantResolver {
+	suffix := "." + strings.TrimPrefix(strings.ToLower(baseDomain), ".")
+
+	return TenantResolverFunc(func(r *http.Request) (Tenant, error) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		sub, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return "", ErrNoTenant
+		}
+
+		return Tenant(sub), nil
+	})
+}
+
+// TenantFromPathPrefix resolves the tenant from the first segment of the
+// request path, e.g. /acme/users gives "acme".
+func TenantFromPathPrefix() TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (Tenant, error) {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		return Tenant(segment), nil
+	})
+}
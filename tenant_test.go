@@ -0,0 +1,47 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTenantResolvers(t *testing.T) {
+	testCases := []struct {
+		name           string
+		resolver       TenantResolver
+		target         string
+		headers        map[string]string
+		expectedTenant Tenant
+		expectedErr    error
+	}{
+		{name: "Header", resolver: TenantFromHeader("X-Tenant"), target: "/users", headers: map[string]string{"X-Tenant": "acme"}, expectedTenant: "acme"},
+		{name: "Missing header", resolver: TenantFromHeader("X-Tenant"), target: "/users", expectedErr: ErrNoTenant},
+		{name: "Subdomain", resolver: TenantFromSubdomain("example.com"), target: "http://acme.example.com:8080/users", expectedTenant: "acme"},
+		{name: "Bare domain", resolver: TenantFromSubdomain("example.com"), target: "http://example.com/users", expectedErr: ErrNoTenant},
+		{name: "Nested subdomain", resolver: TenantFromSubdomain("example.com"), target: "http://a.b.example.com/users", expectedErr: ErrNoTenant},
+		{name: "Path prefix", resolver: TenantFromPathPrefix(), target: "/acme/users", expectedTenant: "acme"},
+	}
+
+	defer SetTenantResolver(nil)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetTenantResolver(tc.resolver)
+
+			req := httptest.NewRequest("GET", tc.target, nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			tenant, err := TenantFromRequest(req)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+
+			if tenant != tc.expectedTenant {
+				t.Errorf("Expected tenant %q, got %q", tc.expectedTenant, tenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package bodyrest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBindTimeout is returned when reading the request body takes longer than
+// the route's bind timeout. It is mapped to 408.
+var ErrBindTimeout = errors.New("request body read timed out")
+
+// ErrHandlerTimeout is returned when the handler does not complete within
+// the route's handler timeout. It is mapped to 504.
+var ErrHandlerTimeout = errors.New("handler timed out")
+
+func init() {
+	RegisterError(ErrBindTimeout, http.StatusRequestTimeout)
+	RegisterError(ErrHandlerTimeout, http.StatusGatewayTimeout)
+}
+
+// WithBindTimeout limits the time spent reading and decoding the request
+// body, so clients trickling a body cannot hold the handler goroutine.
+// Requests exceeding it are answered with 408.
+func WithBindTimeout(d time.Duration) Option {
+	return func(cfg *routeConfig) {
+		cfg.bindTimeout = d
+	}
+}
+
+// WithHandlerTimeout runs the bound handler with a context deadline d from
+// the end of binding. A context.Context param of the handler receives that
+// context. If the handler has not returned by the deadline the request is
+// answered with ErrHandlerTimeout (504) through the error pipeline; the
+// handler keeps running in the background and its results are discarded, so
+// it should return once its context is done. Its bound and provided params
+// are released once it returns. Handlers returning an http.Handler cannot
+// be given a timeout, as the work of the returned handler is not bounded.
+func WithHandlerTimeout(d time.Duration) Option {
+	return func(cfg *routeConfig) {
+		cfg.handlerTimeout = d
+		cfg.wrappers = append(cfg.wrappers, func(next Invoker) Invoker {
+			return func(r *http.Request, args []any) ([]any, error) {
+				ctx, cancel := context.WithTimeout(r.Context(), d)
+				defer cancel()
+
+				return callWithTimeout(ctx, r.WithContext(ctx), withContextArgs(ctx, args), next)
+			}
+		})
+	}
+}
+
+type invokeResult struct {
+	results []any
+	err     error
+	panic   any
+}
+
+// callWithTimeout calls next in its own goroutine and returns its results,
+// or ErrHandlerTimeout once ctx is done. A panic of next is raised again in
+// the calling goroutine, where the handler recovery catches it. On timeout
+// the args of r are handed to the goroutine, which releases them and rolls
+// back the provided params once next returns.
+func callWithTimeout(ctx context.Context, r *http.Request, args []any, next Invoker) ([]any, error) {
+	done := make(chan invokeResult, 1)
+	go func() {
+		var res invokeResult
+		defer func() {
+			res.panic = recover()
+			done <- res
+		}()
+
+		res.results, res.err = next(r, args)
+	}()
+
+	select {
+	case res := <-done:
+		if res.panic != nil {
+			panic(res.panic)
+		}
+		return res.results, res.err
+	case <-ctx.Done():
+		release := detachArgs(r)
+		go func() {
+			<-done
+			release()
+		}()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrHandlerTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
+
+type argsOwnerContextKey struct{}
+
+// argsOwner releases the bound args of a request, unless they have been
+// detached to a handler still running after a timeout.
+type argsOwner struct {
+	mu      sync.Mutex
+	release func()
+}
+
+// withArgsOwner returns r carrying the argsOwner of args.
+func withArgsOwner(r *http.Request, args []reflect.Value) (*http.Request, *argsOwner) {
+	owner := &argsOwner{release: func() { releaseArgs(args) }}
+	return r.WithContext(context.WithValue(r.Context(), argsOwnerContextKey{}, owner)), owner
+}
+
+// releaseArgs releases the args, if they are still owned.
+func (o *argsOwner) releaseArgs() {
+	o.detach()()
+}
+
+// detach returns the function releasing the args and makes releaseArgs a
+// no-op.
+func (o *argsOwner) detach() func() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	release := o.release
+	o.release = func() {}
+	return release
+}
+
+// detachArgs takes the bound args, the provided params and the response
+// hooks of r from the request and returns the function releasing them.
+func detachArgs(r *http.Request) func() {
+	release := func() {}
+	if owner, ok := r.Context().Value(argsOwnerContextKey{}).(*argsOwner); ok {
+		release = owner.detach()
+	}
+
+	rollback := func() {}
+	if provided, ok := r.Context().Value(providedArgsContextKey{}).(*providedArgs); ok {
+		rollback = provided.detach()
+	}
+
+	runHooks := func() {}
+	if hooks, ok := r.Context().Value(responseHooksContextKey{}).(*responseHooks); ok {
+		runHooks = hooks.detach()
+	}
+
+	return func() {
+		rollback()
+		runHooks()
+		release()
+	}
+}
+
+// withContextArgs returns a copy of args with the injected contexts
+// replaced by ctx.
+func withContextArgs(ctx context.Context, args []any) []any {
+	replaced := make([]any, len(args))
+	for i, arg := range args {
+		if _, ok := arg.(context.Context); ok {
+			arg = ctx
+		}
+		replaced[i] = arg
+	}
+
+	return replaced
+}
+
+// limitBindTime applies the bind timeout to the body of r and returns a
+// function lifting it again. The connection read deadline interrupts a
+// blocked read where the server supports it; the body wrapper catches the
+// remaining cases between reads. Lifting it restores the deadline of the
+// server's ReadTimeout rather than clearing it.
+func limitBindTime(w http.ResponseWriter, r *http.Request, d time.Duration) func() {
+	deadline := time.Now().Add(d)
+	serverDeadline := serverReadDeadline(r)
+	if !serverDeadline.IsZero() && serverDeadline.Before(deadline) {
+		deadline = serverDeadline
+	}
+
+	rc := http.NewResponseController(w)
+	hasConnDeadline := rc.SetReadDeadline(deadline) == nil
+
+	var body *deadlineBody
+	if r.Body != nil {
+		body = &deadlineBody{ReadCloser: r.Body}
+		body.deadline.Store(deadline.UnixNano())
+		r.Body = body
+	}
+
+	return func() {
+		if hasConnDeadline {
+			rc.SetReadDeadline(serverDeadline)
+		}
+		if body != nil {
+			body.deadline.Store(0)
+		}
+	}
+}
+
+// serverReadDeadline returns the latest read deadline the server of r can
+// have set from its ReadTimeout, which it counts from the start of the
+// request, or the zero time if it sets none.
+func serverReadDeadline(r *http.Request) time.Time {
+	srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server)
+	if !ok || srv.ReadTimeout <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(srv.ReadTimeout)
+}
+
+type deadlineBody struct {
+	io.ReadCloser
+	deadline atomic.Int64
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	if d := b.deadline.Load(); d != 0 && time.Now().UnixNano() > d {
+		return 0, ErrBindTimeout
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = ErrBindTimeout
+	}
+
+	return n, err
+}
+
+// bodyErrorStatus returns the status for an error reading or decoding the
+// request body.
+func bodyErrorStatus(err error) int {
+	if errors.Is(err, ErrBindTimeout) {
+		return http.StatusRequestTimeout
+	}
+
+	return http.StatusBadRequest
+}
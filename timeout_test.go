@@ -0,0 +1,217 @@
+package bodyrest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// trickleReader returns one byte of body per read, sleeping before each.
+type trickleReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *trickleReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	time.Sleep(r.delay)
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestBindTimeout(t *testing.T) {
+	testCases := []struct {
+		name         string
+		delay        time.Duration
+		expectedCode int
+	}{
+		{
+			name:         "fast body",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "trickled body",
+			delay:        5 * time.Millisecond,
+			expectedCode: http.StatusRequestTimeout,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := &trickleReader{data: []byte(`{"name":"John Doe"}`), delay: tc.delay}
+			req := httptest.NewRequest("POST", "/users", body)
+			req.ContentLength = int64(len(body.data))
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(testCreateUser, WithBindTimeout(20*time.Millisecond)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func testSlowLookup(ctx context.Context, delay int) (int, error) {
+	if delay < 0 {
+		panic("negative delay")
+	}
+
+	select {
+	case <-time.After(time.Duration(delay) * time.Millisecond):
+		return delay, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Completes in time",
+			path:           "/lookups/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "1\n",
+		},
+		{
+			name:           "Times out",
+			path:           "/lookups/1000",
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "Handler panics",
+			path:           "/lookups/-1",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Get("/lookups/{delay}", HandleTo(testSlowLookup, WithHandlerTimeout(50*time.Millisecond)))
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type testSlowTx struct {
+	done chan struct{}
+}
+
+func TestHandlerTimeoutRollsBackAfterHandler(t *testing.T) {
+	rolledBack := make(chan bool, 1)
+	RegisterProvider(Provider[*testSlowTx]{
+		Begin: func(r *http.Request) (*testSlowTx, error) { return &testSlowTx{done: make(chan struct{})}, nil },
+		Rollback: func(tx *testSlowTx) error {
+			select {
+			case <-tx.done:
+				rolledBack <- true
+			default:
+				rolledBack <- false
+			}
+			return nil
+		},
+	})
+
+	r := chi.NewRouter()
+	r.Get("/reports", HandleTo(func(tx *testSlowTx) (string, error) {
+		time.Sleep(100 * time.Millisecond)
+		close(tx.done)
+		return "report", nil
+	}, WithHandlerTimeout(20*time.Millisecond)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/reports", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected status code %d, got %d", http.StatusGatewayTimeout, w.Code)
+	}
+
+	select {
+	case afterHandler := <-rolledBack:
+		if !afterHandler {
+			t.Error("Expected the transaction to be rolled back after the handler returned")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the transaction to be rolled back")
+	}
+}
+
+// deadlineRecorder records the read deadlines set through
+// http.ResponseController.
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	deadlines []time.Time
+}
+
+func (w *deadlineRecorder) SetReadDeadline(deadline time.Time) error {
+	w.deadlines = append(w.deadlines, deadline)
+	return nil
+}
+
+func TestBindTimeoutRestoresReadDeadline(t *testing.T) {
+	testCases := []struct {
+		name              string
+		server            *http.Server
+		expectedRemaining time.Duration
+	}{
+		{name: "no server", expectedRemaining: 0},
+		{name: "no read timeout", server: &http.Server{}, expectedRemaining: 0},
+		{name: "read timeout", server: &http.Server{ReadTimeout: time.Minute}, expectedRemaining: time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users", nil)
+			if tc.server != nil {
+				req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, tc.server))
+			}
+			w := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+			limitBindTime(w, req, time.Second)()
+
+			if len(w.deadlines) != 2 {
+				t.Fatalf("Expected 2 read deadlines, got %d", len(w.deadlines))
+			}
+
+			restored := w.deadlines[1]
+			if tc.expectedRemaining == 0 {
+				if !restored.IsZero() {
+					t.Errorf("Expected no read deadline, got %s", restored)
+				}
+				return
+			}
+
+			if remaining := time.Until(restored); remaining <= 0 || remaining > tc.expectedRemaining {
+				t.Errorf("Expected read deadline within %s, got %s", tc.expectedRemaining, remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package bodyrest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HTTPRule maps an RPC method to a REST route like a google.api.http
+// annotation. Handler is the service method, e.g. svc.GetUser of type
+// func(context.Context, *GetUserRequest) (*User, error). Body is "*" to
+// decode the body into the whole request message, the name of a request
+// field to decode it into that field, or empty for no body.
+//
+// Rules are read from the annotations of a service descriptor by
+// protohttp.Rules, in the protohttp module, which keeps the protobuf
+// dependency out of bodyrest. Written by hand, Pattern is in chi syntax:
+// "/v1/users/{user_id}" for get: "/v1/users/{user_id}".
+type HTTPRule struct {
+	Method  string
+	Pattern string
+	Body    string
+	Handler any
+}
+
+// Transcode registers a route on rt for each rule. Path params, and for rules
+// without a "*" body the query params, are bound to the request message
+// fields with the same proto or JSON name; dotted names such as
+// {user.id} bind nested messages. Query params matching no field are
+// ignored. Messages are encoded with encoding/json,
+// so they follow their json tags rather than protojson.
+func Transcode(rt *Router, rules ...HTTPRule) {
+	for _, rule := range rules {
+		rt.register(rule.Method, rule.Pattern, transcodeHandler(rule), RouteInfo{
+			Method:      rule.Method,
+			Pattern:     rule.Pattern,
+			HandlerType: reflect.TypeOf(rule.Handler),
+		})
+	}
+}
+
+func transcodeHandler(rule HTTPRule) http.HandlerFunc {
+	fn := reflect.ValueOf(rule.Handler)
+	fnType := fn.Type()
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 2 || fnType.In(0) != contextType ||
+		fnType.In(1).Kind() != reflect.Ptr || fnType.In(1).Elem().Kind() != reflect.Struct ||
+		fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+		log.Fatalf("transcoded handler for %s %s must be func(context.Context, *Request) (Response, error)", rule.Method, rule.Pattern)
+	}
+
+	cfg := newRouteConfig(nil)
+	messageType := fnType.In(1).Elem()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer recoverHandler(w, r)
+
+		finish, ok := admitRequest(w, r)
+		if !ok {
+			return
+		}
+		defer finish()
+
+		message := reflect.New(messageType)
+		if err := bindMessage(w, r, cfg, rule.Body, message); err != nil {
+			log.Printf("failed to bind %s: %v\n", messageType, err)
+			status := bodyErrorStatus(err)
+			if errors.Is(err, errUnknownMessageField) {
+				status = http.StatusInternalServerError
+			}
+			writeError(w, r, status, err)
+			return
+		}
+
+		out := fn.Call([]reflect.Value{reflect.ValueOf(r.Context()), message})
+		if err, _ := out[1].Interface().(error); err != nil {
+			log.Printf("handler returned error: %v\n", err)
+			writeError(w, r, statusFromError(err), err)
+			return
+		}
+
+		writeResponse(w, r, cfg, responseOf(http.StatusOK, out[0]))
+	}
+}
+
+// bindMessage fills the request message from the body, the path and the
+// query, in that order.
+func bindMessage(w http.ResponseWriter, r *http.Request, cfg *routeConfig, body string, message reflect.Value) error {
+	if body != "" && r.Body != nil && r.ContentLength != 0 {
+		target := message
+		if body != "*" {
+			field, err := messageField(message.Elem(), body)
+			if err != nil {
+				return err
+			}
+			if field.Kind() != reflect.Ptr {
+				field = field.Addr()
+			} else if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			if field.Elem().Kind() != reflect.Struct {
+				return fmt.Errorf("%w %q: body field must be a message", errUnknownMessageField, body)
+			}
+			target = field
+		}
+
+		if err := decodeBody(w, r, cfg, target.Interface()); err != nil {
+			return err
+		}
+	}
+
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		for i, key := range rctx.URLParams.Keys {
+			if key == "*" {
+				continue
+			}
+
+			value, err := decodePathParam(r, rctx.URLParams.Values[i])
+			if err != nil {
+				return err
+			}
+			if err := setMessageField(message.Elem(), key, []string{value}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if body == "*" {
+		return nil
+	}
+
+	for key, values := range r.URL.Query() {
+		err := setMessageField(message.Elem(), key, values)
+		if err != nil && !errors.Is(err, errUnknownMessageField) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setMessageField(message reflect.Value, path string, values []string) error {
+	field, err := messageField(message, path)
+	if err != nil {
+		return err
+	}
+
+	if err := setFieldFromStrings(field, values); err != nil {
+		return fmt.Errorf("field %q: %w", path, err)
+	}
+
+	return nil
+}
+
+var errUnknownMessageField = errors.New("unknown field")
+
+// messageField returns the field of message at the dotted path of proto or
+// JSON field names, allocating nested messages on the way.
+func messageField(message reflect.Value, path string) (reflect.Value, error) {
+	v := message
+	for _, name := range strings.Split(path, ".") {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w %q", errUnknownMessageField, path)
+		}
+
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() && isMessageFieldNamed(field, name) {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("%w %q", errUnknownMessageField, path)
+		}
+	}
+
+	return v, nil
+}
+
+// isMessageFieldNamed matches name against the protobuf tag of field, as in
+// protobuf:"bytes,1,opt,name=user_id,json=userId", its JSON name and its Go
+// name.
+func isMessageFieldNamed(field reflect.StructField, name string) bool {
+	for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if part == "name="+name || part == "json="+name {
+			return true
+		}
+	}
+
+	return jsonFieldName(field) == name || strings.EqualFold(field.Name, strings.ReplaceAll(name, "_", ""))
+}
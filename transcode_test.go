@@ -0,0 +1,95 @@
+package bodyrest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testProtoName struct {
+	First string `protobuf:"bytes,1,opt,name=first,proto3" json:"first,omitempty"`
+}
+
+type testProtoUser struct {
+	UserId int64          `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name   *testProtoName `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Tags   []string       `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+type testProtoUpdateRequest struct {
+	Org  string         `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	User *testProtoUser `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+type testUserService struct{}
+
+func (testUserService) GetUser(ctx context.Context, req *testProtoUser) (*testProtoUser, error) {
+	if req.UserId == 0 {
+		return nil, errTestUserExists
+	}
+	return req, nil
+}
+
+func (testUserService) UpdateUser(ctx context.Context, req *testProtoUpdateRequest) (*testProtoUpdateRequest, error) {
+	return req, nil
+}
+
+func TestTranscode(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	svc := testUserService{}
+	rt := NewRouter(nil)
+	Transcode(rt,
+		HTTPRule{Method: http.MethodGet, Pattern: "/v1/users/{user_id}", Handler: svc.GetUser},
+		HTTPRule{Method: http.MethodPatch, Pattern: "/v1/orgs/{org}/users/{user.user_id}", Body: "user", Handler: svc.UpdateUser},
+		HTTPRule{Method: http.MethodPost, Pattern: "/v1/users", Body: "*", Handler: svc.GetUser},
+	)
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "path and query", method: "GET", url: "/v1/users/7?tags=a&tags=b&name.first=john&unknown=1", expectedStatus: http.StatusOK, expectedBody: `{"user_id":7,"name":{"first":"john"},"tags":["a","b"]}`},
+		{name: "body field", method: "PATCH", url: "/v1/orgs/acme/users/7", body: `{"name":{"first":"jo"}}`, expectedStatus: http.StatusOK, expectedBody: `{"org":"acme","user":{"user_id":7,"name":{"first":"jo"}}}`},
+		{name: "whole body", method: "POST", url: "/v1/users?tags=ignored", body: `{"user_id":3}`, expectedStatus: http.StatusOK, expectedBody: `{"user_id":3}`},
+		{name: "invalid path param", method: "GET", url: "/v1/users/abc", expectedStatus: http.StatusBadRequest},
+		{name: "service error", method: "GET", url: "/v1/users/0", expectedStatus: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTranscodeRouteInfo(t *testing.T) {
+	svc := testUserService{}
+	rt := NewRouter(nil)
+	Transcode(rt, HTTPRule{Method: http.MethodGet, Pattern: "/v1/users/{user_id}", Handler: svc.GetUser})
+
+	routes := rt.Routes()
+	if len(routes) != 1 {
+		t.Fatalf("Expected 1 route, got %d", len(routes))
+	}
+	if routes[0].HandlerType != reflect.TypeOf(svc.GetUser) {
+		t.Errorf("Expected handler type %s, got %v", reflect.TypeOf(svc.GetUser), routes[0].HandlerType)
+	}
+}
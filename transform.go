@@ -0,0 +1,75 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// BodyTransformFunc rewrites the raw request body before it is decoded, e.g.
+// to decrypt a JWE payload.
+type BodyTransformFunc func(body []byte, header http.Header) ([]byte, error)
+
+// WithBodyTransform runs transform on the raw body of every request of the
+// route, after checksums and signatures are verified and before decoding.
+// Transform errors are rejected with 400.
+func WithBodyTransform(transform BodyTransformFunc) Option {
+	return func(cfg *routeConfig) {
+		cfg.bodyTransforms = append(cfg.bodyTransforms, transform)
+	}
+}
+
+// DecryptFields returns a body transform that replaces the string values at
+// the given dotted paths with the JSON value returned by decrypt, for
+// payloads whose sensitive fields are encrypted one by one.
+func DecryptFields(decrypt func(ciphertext string) (json.RawMessage, error), paths ...string) BodyTransformFunc {
+	return func(body []byte, header http.Header) ([]byte, error) {
+		var doc map[string]any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			if err := decryptPath(doc, strings.Split(path, "."), decrypt); err != nil {
+				return nil, fmt.Errorf("field %s: %w", path, err)
+			}
+		}
+
+		return json.Marshal(doc)
+	}
+}
+
+func decryptPath(doc map[string]any, path []string, decrypt func(string) (json.RawMessage, error)) error {
+	value, ok := doc[path[0]]
+	if !ok {
+		return nil
+	}
+
+	if len(path) > 1 {
+		child, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		return decryptPath(child, path[1:], decrypt)
+	}
+
+	ciphertext, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("encrypted value must be a string")
+	}
+
+	plaintext, err := decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(plaintext) {
+		return fmt.Errorf("decrypted value is not valid JSON")
+	}
+
+	doc[path[0]] = plaintext
+	return nil
+}
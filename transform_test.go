@@ -0,0 +1,108 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testPaymentRequest struct {
+	Card struct {
+		Number string `json:"number"`
+	} `json:"card"`
+	Amount int `json:"amount"`
+}
+
+func testPay(req testPaymentRequest) (string, error) {
+	return req.Card.Number, nil
+}
+
+func TestBodyTransforms(t *testing.T) {
+	decodeBase64 := func(ciphertext string) (json.RawMessage, error) {
+		plaintext, err := base64.StdEncoding.DecodeString(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(plaintext), nil
+	}
+
+	wholeBody := func(body []byte, header http.Header) ([]byte, error) {
+		return base64.StdEncoding.DecodeString(string(body))
+	}
+
+	encryptedNumber := base64.StdEncoding.EncodeToString([]byte(`"4242"`))
+
+	testCases := []struct {
+		name           string
+		jsonPayload    string
+		opt            Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Encrypted field",
+			jsonPayload:    `{"card":{"number":"` + encryptedNumber + `"},"amount":10}`,
+			opt:            WithBodyTransform(DecryptFields(decodeBase64, "card.number")),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"4242"`,
+		},
+		{
+			name:           "Undecryptable field",
+			jsonPayload:    `{"card":{"number":"!!"},"amount":10}`,
+			opt:            WithBodyTransform(DecryptFields(decodeBase64, "card.number")),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+		{
+			name:           "Encrypted body",
+			jsonPayload:    base64.StdEncoding.EncodeToString([]byte(`{"card":{"number":"1111"},"amount":10}`)),
+			opt:            WithBodyTransform(wholeBody),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"1111"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/payments", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/payments", HandleTo(testPay, tc.opt))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDecryptFieldsKeepsNumbers(t *testing.T) {
+	decrypt := func(ciphertext string) (json.RawMessage, error) {
+		return json.RawMessage(`"` + ciphertext + `"`), nil
+	}
+
+	body, err := DecryptFields(decrypt, "card.number")([]byte(`{"card":{"number":"4242"},"amount":9007199254740993}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"amount":9007199254740993,"card":{"number":"4242"}}`
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+}
@@ -0,0 +1,201 @@
+package bodyrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+var twirpPrefix = "/twirp"
+
+// SetTwirpPrefix sets the path prefix of routes registered with Twirp,
+// "/twirp" by default.
+func SetTwirpPrefix(prefix string) {
+	twirpPrefix = prefix
+}
+
+// ProtoCodec is implemented by message types that encode themselves in the
+// protobuf wire format, like gogo/protobuf generated types. Twirp routes
+// accept application/protobuf requests only for handlers whose request and
+// response types implement it.
+type ProtoCodec interface {
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+var protoCodecType = typeOf[ProtoCodec]()
+
+// Twirp registers methods, bodyrest handlers by method name, under Twirp's
+// POST {prefix}/{service}/{method} routes, e.g.
+//
+//	bodyrest.Twirp(rt, "example.v1.Users", map[string]any{"GetUser": getUser})
+//
+// Requests are bound and handled as with HandleTo and opts. Bodies may be
+// application/json or, for ProtoCodec messages, application/protobuf.
+// Errors are answered in Twirp's JSON error format with the code matching
+// their status.
+func Twirp(rt *Router, service string, methods map[string]any, opts ...Option) {
+	for name, handlerFunc := range methods {
+		pattern := twirpPrefix + "/" + service + "/" + name
+		rt.register(http.MethodPost, pattern, twirpHandler(handlerFunc, opts), RouteInfo{
+			Method:      http.MethodPost,
+			Pattern:     pattern,
+			HandlerType: reflect.TypeOf(handlerFunc),
+		})
+	}
+}
+
+func twirpHandler(handlerFunc any, opts []Option) http.HandlerFunc {
+	handlerType := reflect.TypeOf(handlerFunc)
+	handler := HandleTo(handlerFunc, opts...)
+
+	var reqType, respType reflect.Type
+	if bodyType, ok := requestBodyType(handlerType); ok && isProtoCodec(bodyType) {
+		reqType = bodyType
+	}
+	if resultFormOf(handlerType) == resultValue && isProtoCodec(handlerType.Out(0)) {
+		respType = handlerType.Out(0)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		protobuf := mediaType == "application/protobuf"
+		if !protobuf && mediaType != "application/json" || protobuf && (reqType == nil || respType == nil) {
+			writeTwirpError(w, http.StatusNotFound, "bad_route", "unsupported Content-Type: "+mediaType)
+			return
+		}
+
+		if protobuf {
+			var err error
+			if r, err = protobufToJSON(r, reqType); err != nil {
+				log.Printf("failed to decode protobuf request: %v\n", err)
+				writeTwirpError(w, http.StatusBadRequest, "malformed", "the request could not be decoded")
+				return
+			}
+		}
+
+		buf := &bufferedResponseWriter{header: http.Header{}}
+		handler.ServeHTTP(buf, r)
+
+		if status := buf.statusCode(); status >= http.StatusBadRequest {
+			writeTwirpError(w, status, twirpCode(status), http.StatusText(status))
+			return
+		}
+
+		body := buf.body.Bytes()
+		if protobuf {
+			var err error
+			if body, err = jsonToProtobuf(body, respType); err != nil {
+				log.Printf("failed to encode protobuf response: %v\n", err)
+				writeTwirpError(w, http.StatusInternalServerError, "internal", http.StatusText(http.StatusInternalServerError))
+				return
+			}
+			buf.header = http.Header{"Content-Type": []string{"application/protobuf"}}
+		}
+
+		for key, values := range buf.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(body); err != nil {
+			log.Printf("failed to write response body: %v\n", err)
+		}
+	}
+}
+
+func isProtoCodec(t reflect.Type) bool {
+	return t.Implements(protoCodecType) || reflect.PointerTo(t).Implements(protoCodecType)
+}
+
+// newProtoCodec returns a new value of t as a ProtoCodec and the value to
+// hand to encoding/json.
+func newProtoCodec(t reflect.Type) (ProtoCodec, any) {
+	if t.Kind() == reflect.Ptr {
+		v := reflect.New(t.Elem()).Interface()
+		return v.(ProtoCodec), v
+	}
+
+	v := reflect.New(t).Interface()
+	return v.(ProtoCodec), v
+}
+
+// protobufToJSON returns r with its protobuf body re-encoded as JSON for the
+// binding stack.
+func protobufToJSON(r *http.Request, t reflect.Type) (*http.Request, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, v := newProtoCodec(t)
+	if err := msg.Unmarshal(data); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r = r.Clone(r.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Del("Accept-Encoding")
+	return r, nil
+}
+
+func jsonToProtobuf(body []byte, t reflect.Type) ([]byte, error) {
+	msg, v := newProtoCodec(t)
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, err
+	}
+
+	return msg.Marshal()
+}
+
+// twirpCode returns the Twirp error code for an HTTP status.
+func twirpCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_argument"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusRequestTimeout:
+		return "deadline_exceeded"
+	case http.StatusConflict:
+		return "already_exists"
+	case http.StatusPreconditionFailed:
+		return "failed_precondition"
+	case http.StatusTooManyRequests:
+		return "resource_exhausted"
+	case http.StatusNotImplemented:
+		return "unimplemented"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	case http.StatusInternalServerError:
+		return "internal"
+	}
+
+	return "unknown"
+}
+
+func writeTwirpError(w http.ResponseWriter, status int, code, msg string) {
+	body, _ := json.Marshal(map[string]string{"code": code, "msg": msg})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("failed to write response body: %v\n", err)
+	}
+}
@@ -0,0 +1,81 @@
+package bodyrest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testProtoGreeting stands in for a generated message, with a wire format of
+// just its text.
+type testProtoGreeting struct {
+	Text string `json:"text"`
+}
+
+func (g *testProtoGreeting) Marshal() ([]byte, error) {
+	return []byte(g.Text), nil
+}
+
+func (g *testProtoGreeting) Unmarshal(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty message")
+	}
+	g.Text = string(data)
+	return nil
+}
+
+func TestTwirp(t *testing.T) {
+	RegisterError(errTestUserExists, http.StatusConflict)
+
+	rt := NewRouter(nil)
+	Twirp(rt, "example.v1.Greeter", map[string]any{
+		"Greet": func(g testProtoGreeting) (testProtoGreeting, error) {
+			if g.Text == "exists" {
+				return testProtoGreeting{}, errTestUserExists
+			}
+			return testProtoGreeting{Text: "hello " + g.Text}, nil
+		},
+		"CreateUser": testImportUser,
+	})
+
+	testCases := []struct {
+		name                string
+		path                string
+		contentType         string
+		body                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{name: "json", path: "/twirp/example.v1.Greeter/Greet", contentType: "application/json", body: `{"text":"john"}`, expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedBody: `{"text":"hello john"}`},
+		{name: "protobuf", path: "/twirp/example.v1.Greeter/Greet", contentType: "application/protobuf", body: "john", expectedStatus: http.StatusOK, expectedContentType: "application/protobuf", expectedBody: "hello john"},
+		{name: "malformed protobuf", path: "/twirp/example.v1.Greeter/Greet", contentType: "application/protobuf", expectedStatus: http.StatusBadRequest, expectedContentType: "application/json", expectedBody: `{"code":"malformed","msg":"the request could not be decoded"}`},
+		{name: "protobuf unsupported by handler", path: "/twirp/example.v1.Greeter/CreateUser", contentType: "application/protobuf", body: "john", expectedStatus: http.StatusNotFound, expectedContentType: "application/json", expectedBody: `{"code":"bad_route","msg":"unsupported Content-Type: application/protobuf"}`},
+		{name: "handler error", path: "/twirp/example.v1.Greeter/Greet", contentType: "application/json", body: `{"text":"exists"}`, expectedStatus: http.StatusConflict, expectedContentType: "application/json", expectedBody: `{"code":"already_exists","msg":"Conflict"}`},
+		{name: "binding error", path: "/twirp/example.v1.Greeter/CreateUser", contentType: "application/json", body: `{"name":`, expectedStatus: http.StatusBadRequest, expectedContentType: "application/json", expectedBody: `{"code":"invalid_argument","msg":"Bad Request"}`},
+		{name: "other content type", path: "/twirp/example.v1.Greeter/Greet", contentType: "text/plain", body: "john", expectedStatus: http.StatusNotFound, expectedContentType: "application/json", expectedBody: `{"code":"bad_route","msg":"unsupported Content-Type: text/plain"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if contentType := w.Header().Get("Content-Type"); contentType != tc.expectedContentType {
+				t.Errorf("Expected Content-Type %q, got %q", tc.expectedContentType, contentType)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldViolation describes a single invalid field of a request body.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is passed to the rest error handler when a request body
+// fails validation. Unless SetCollectAllViolations is enabled it holds only
+// the first violation found.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+var collectAllViolations bool
+
+// SetCollectAllViolations makes validation check every field and report all
+// violations in one ValidationError instead of stopping at the first one.
+func SetCollectAllViolations(enabled bool) {
+	collectAllViolations = enabled
+}
+
+// validateRequiredFields checks that required fields are not empty and
+// returns a *ValidationError if any are.
+func validateRequiredFields(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot validate %s: %w", value.Kind(), errors.ErrUnsupported)
+	}
+
+	var violations []FieldViolation
+	collectViolations(value, "", &violations)
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// collectViolations appends the violations of the struct value to
+// violations, with field names prefixed by prefix. Only the fields of
+// generic structs such as Envelope[T]{Data T} are descended into, embedded
+// or nested, including slice elements, so their instantiated type params are
+// validated like top-level bodies; nested structs of other types are left
+// unchecked. It returns false once it should stop looking for more
+// violations.
+func collectViolations(value reflect.Value, prefix string, violations *[]FieldViolation) bool {
+	generic := isGenericType(value.Type())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		// unexported fields are never decoded, e.g. in inline struct bodies
+		if !field.IsExported() {
+			continue
+		}
+
+		if generic && isEmbeddedStruct(field) {
+			if !collectViolations(fieldValue, prefix, violations) {
+				return false
+			}
+			continue
+		}
+
+		name := prefix + jsonFieldName(field)
+		required := isFieldRequired(field)
+		if required && (isFieldEmpty(fieldValue) || (isUnmarshaler(field.Type) && fieldValue.IsZero())) {
+			*violations = append(*violations, FieldViolation{
+				Field:   name,
+				Message: violationMessage(field, "is required"),
+			})
+
+			if !collectAllViolations {
+				return false
+			}
+			continue
+		}
+
+		if generic && (required || !fieldValue.IsZero()) && !collectNestedViolations(fieldValue, name, violations) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isGenericType reports whether t is an instantiated generic type, whose
+// name lists its type arguments, e.g. "Envelope[main.User]".
+func isGenericType(t reflect.Type) bool {
+	return strings.Contains(t.Name(), "[")
+}
+
+// collectNestedViolations validates the structs held by the field value
+// named name.
+func collectNestedViolations(value reflect.Value, name string, violations *[]FieldViolation) bool {
+	if isUnmarshaler(value.Type()) {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return true
+		}
+		return collectNestedViolations(value.Elem(), name, violations)
+	case reflect.Struct:
+		return collectViolations(value, name+".", violations)
+	case reflect.Slice, reflect.Array:
+		elem := value.Type().Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return true
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if !collectNestedViolations(value.Index(i), fmt.Sprintf("%s[%d]", name, i), violations) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isFieldRequired reports whether field must not be empty. Fields with a json
+// tag without omitempty are required, except for types that decode
+// themselves through json.Unmarshaler, whose emptiness bodyrest cannot judge.
+// A required:"true" or required:"false" tag overrides both rules.
+func isFieldRequired(field reflect.StructField) bool {
+	if tag, ok := field.Tag.Lookup("required"); ok {
+		required, _ := strconv.ParseBool(tag)
+		return required
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "" || tag == "-" || strings.Contains(tag, "omitempty") {
+		return false
+	}
+
+	return !isUnmarshaler(field.Type)
+}
+
+func isUnmarshaler(t reflect.Type) bool {
+	return t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+}
+
+// violationMessage returns the errmsg tag of field, if set, so products can
+// choose the wording clients see, or the default message otherwise.
+func violationMessage(field reflect.StructField, message string) string {
+	if custom := field.Tag.Get("errmsg"); custom != "" {
+		return custom
+	}
+
+	return message
+}
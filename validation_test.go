@@ -0,0 +1,287 @@
+package bodyrest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestValidationViolations(t *testing.T) {
+	testCases := []struct {
+		name       string
+		collectAll bool
+		expected   []string
+	}{
+		{
+			name:     "first violation",
+			expected: []string{"name"},
+		},
+		{
+			name:       "all violations",
+			collectAll: true,
+			expected:   []string{"name", "email"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetCollectAllViolations(tc.collectAll)
+			defer SetCollectAllViolations(false)
+
+			var reported error
+			saved := restErrorFunc
+			restErrorFunc = func(w http.ResponseWriter, r *http.Request, status int) {
+				reported = ErrorFromRequest(r)
+				w.WriteHeader(status)
+			}
+			defer func() { restErrorFunc = saved }()
+
+			req := httptest.NewRequest("POST", "/signup", strings.NewReader(`{"age":30}`))
+
+			r := chi.NewRouter()
+			r.Post("/signup", HandleTo(func(req struct {
+				Name  string `json:"name"`
+				Email string `json:"email"`
+				Age   int    `json:"age"`
+			}) (int, any, error) {
+				return http.StatusNoContent, nil, nil
+			}))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(reported, &validationErr) {
+				t.Fatalf("Expected ValidationError, got %v", reported)
+			}
+
+			if len(validationErr.Violations) != len(tc.expected) {
+				t.Fatalf("Expected %d violations, got %v", len(tc.expected), validationErr.Violations)
+			}
+
+			for i, field := range tc.expected {
+				if validationErr.Violations[i].Field != field {
+					t.Errorf("Expected violation for %s, got %s", field, validationErr.Violations[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrmsgTag(t *testing.T) {
+	err := validateRequiredFields(&struct {
+		Email string `json:"email" errmsg:"a valid email is required"`
+	}{})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+
+	expected := "a valid email is required"
+	if validationErr.Violations[0].Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, validationErr.Violations[0].Message)
+	}
+}
+
+type testStatus string
+
+func (s *testStatus) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*s = testStatus(strings.ToLower(v))
+	return nil
+}
+
+func TestValidationUnmarshalerFields(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   any
+		isValid bool
+	}{
+		{
+			name: "unmarshaler skipped",
+			value: &struct {
+				Status testStatus `json:"status"`
+			}{},
+			isValid: true,
+		},
+		{
+			name: "unmarshaler required by tag",
+			value: &struct {
+				Status testStatus `json:"status" required:"true"`
+			}{},
+			isValid: false,
+		},
+		{
+			name: "required disabled by tag",
+			value: &struct {
+				Name string `json:"name" required:"false"`
+			}{},
+			isValid: true,
+		},
+		{
+			name: "unmarshaler set",
+			value: &struct {
+				Status testStatus `json:"status" required:"true"`
+			}{Status: "active"},
+			isValid: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRequiredFields(tc.value)
+			if (err == nil) != tc.isValid {
+				t.Errorf("Expected valid %v, got %v", tc.isValid, err)
+			}
+		})
+	}
+}
+
+func TestInlineStructBody(t *testing.T) {
+	handler := func(req struct {
+		Name  string `json:"name"`
+		Email string `json:"email,omitempty"`
+		Tags  []struct {
+			Key string `json:"key"`
+		} `json:"tags,omitempty"`
+		internal string `required:"true"`
+	}) (string, error) {
+		return req.Name + ":" + req.Email, nil
+	}
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "valid", body: `{"name":"John","tags":[{"key":"a"}]}`, expectedStatus: http.StatusOK, expectedBody: `"John:"`},
+		{name: "optional field", body: `{"name":"John","email":"j@example.com"}`, expectedStatus: http.StatusOK, expectedBody: `"John:j@example.com"`},
+		{name: "missing required field", body: `{"email":"j@example.com"}`, expectedStatus: http.StatusBadRequest},
+		{name: "wrong type", body: `{"name":1}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Post("/users", HandleTo(handler))
+
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type testEnvelope[T any] struct {
+	Data T      `json:"data"`
+	Meta string `json:"meta,omitempty"`
+}
+
+type testPage[T any] struct {
+	Items []T `json:"items"`
+}
+
+type testShipment struct {
+	To testUser `json:"to"`
+}
+
+func TestGenericRequestBody(t *testing.T) {
+	SetCollectAllViolations(true)
+	defer SetCollectAllViolations(false)
+
+	testCases := []struct {
+		name           string
+		handler        any
+		body           string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:           "valid envelope",
+			handler:        func(req testEnvelope[testUser]) (string, error) { return req.Data.Name, nil },
+			body:           `{"data":{"name":"John"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing field of type param",
+			handler:        func(req testEnvelope[testUser]) (string, error) { return req.Data.Name, nil },
+			body:           `{"data":{},"meta":"x"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"data.name"},
+		},
+		{
+			name:           "missing field of slice elements",
+			handler:        func(req testEnvelope[testPage[testUser]]) (int, error) { return len(req.Data.Items), nil },
+			body:           `{"data":{"items":[{"name":"John"},{}]}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"data.items[1].name"},
+		},
+		{
+			name:           "nested struct of non-generic type not validated",
+			handler:        func(req testShipment) (string, error) { return req.To.Name, nil },
+			body:           `{"to":{}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "pointer type param",
+			handler:        func(req testEnvelope[*testUser]) (string, error) { return req.Data.Name, nil },
+			body:           `{"data":{"name":""}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"data.name"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fields []string
+			saved := restErrorFunc
+			restErrorFunc = func(w http.ResponseWriter, r *http.Request, status int) {
+				var verr *ValidationError
+				if errors.As(ErrorFromRequest(r), &verr) {
+					for _, v := range verr.Violations {
+						fields = append(fields, v.Field)
+					}
+				}
+				w.WriteHeader(status)
+			}
+			defer func() { restErrorFunc = saved }()
+
+			r := chi.NewRouter()
+			r.Post("/envelopes", HandleTo(tc.handler))
+
+			req := httptest.NewRequest("POST", "/envelopes", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.Join(fields, ",") != strings.Join(tc.expectedFields, ",") {
+				t.Errorf("Expected violations of %v, got %v", tc.expectedFields, fields)
+			}
+		})
+	}
+}
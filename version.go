@@ -0,0 +1,83 @@
+package bodyrest
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// APIVersionHeader is the request header that selects a handler version.
+const APIVersionHeader = "X-API-Version"
+
+// Versions maps API versions to handler functions accepted by HandleTo.
+type Versions map[string]interface{}
+
+// WithDefaultVersion sets the version used by HandleVersions when the request
+// does not ask for one.
+func WithDefaultVersion(version string) Option {
+	return func(cfg *routeConfig) {
+		cfg.defaultVersion = version
+	}
+}
+
+// HandleVersions registers one handler per API version on the same route.
+// The version is taken from the X-API-Version header or the version
+// parameter of the Accept header (application/json; version=2), and each
+// version binds its own request struct. Unknown versions are rejected with
+// 400.
+func HandleVersions(versions Versions, opts ...Option) http.HandlerFunc {
+	if len(versions) == 0 {
+		log.Fatal("no handler versions given")
+	}
+
+	cfg := newRouteConfig(opts)
+	handlers := make(map[string]http.HandlerFunc, len(versions))
+	for version, handlerFunc := range versions {
+		handlers[version] = HandleTo(handlerFunc, opts...)
+	}
+
+	if _, ok := handlers[cfg.defaultVersion]; cfg.defaultVersion != "" && !ok {
+		log.Fatalf("default version %s has no handler", cfg.defaultVersion)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", APIVersionHeader)
+		w.Header().Add("Vary", "Accept")
+
+		version := requestedVersion(r)
+		if version == "" {
+			version = cfg.defaultVersion
+		}
+
+		handler, ok := handlers[version]
+		if !ok {
+			err := fmt.Errorf("unsupported API version %q", version)
+			log.Println(err)
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set(APIVersionHeader, version)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func requestedVersion(r *http.Request) string {
+	if version := strings.TrimSpace(r.Header.Get(APIVersionHeader)); version != "" {
+		return version
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if version := params["version"]; version != "" {
+			return version
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,99 @@
+package bodyrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testUserV2 struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+func testCreateUserV2(u testUserV2) (testUserV2, error) {
+	return u, nil
+}
+
+func TestHandleVersions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		jsonPayload     string
+		headers         map[string]string
+		expectedStatus  int
+		expectedBody    string
+		expectedVersion string
+	}{
+		{
+			name:            "Default version",
+			jsonPayload:     `{"name":"john"}`,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"name":"JOHN"}`,
+			expectedVersion: "1",
+		},
+		{
+			name:            "Version header",
+			jsonPayload:     `{"firstName":"john","lastName":"doe"}`,
+			headers:         map[string]string{"X-API-Version": "2"},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"firstName":"john","lastName":"doe"}`,
+			expectedVersion: "2",
+		},
+		{
+			name:            "Accept version parameter",
+			jsonPayload:     `{"firstName":"john","lastName":"doe"}`,
+			headers:         map[string]string{"Accept": "application/json; version=2"},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"firstName":"john","lastName":"doe"}`,
+			expectedVersion: "2",
+		},
+		{
+			name:            "Version 2 binds its own struct",
+			jsonPayload:     `{"name":"john"}`,
+			headers:         map[string]string{"X-API-Version": "2"},
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"message":"Error while parsing request. Please check your request and try again."}`,
+			expectedVersion: "2",
+		},
+		{
+			name:           "Unknown version",
+			jsonPayload:    `{"name":"john"}`,
+			headers:        map[string]string{"X-API-Version": "3"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Error while parsing request. Please check your request and try again."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/users", bytes.NewBufferString(tc.jsonPayload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/users", HandleVersions(Versions{"1": testImportUser, "2": testCreateUserV2}, WithDefaultVersion("1")))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if strings.TrimSpace(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+
+			if w.Header().Get("X-API-Version") != tc.expectedVersion {
+				t.Errorf("Expected version %q, got %q", tc.expectedVersion, w.Header().Get("X-API-Version"))
+			}
+		})
+	}
+}
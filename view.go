@@ -0,0 +1,35 @@
+package bodyrest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+const viewQueryParam = "view"
+
+// WithView encodes the route's responses in the named view: struct fields
+// tagged e.g. view:"summary,detail" are only encoded in the listed views,
+// untagged fields in all of them. Clients may pick one of selectable with
+// the view query parameter, e.g. ?view=detail.
+func WithView(view string, selectable ...string) Option {
+	return func(cfg *routeConfig) {
+		cfg.view = view
+		cfg.selectableViews = selectable
+	}
+}
+
+// requestedView returns the view to encode the response of r in, or an
+// error if the client asked for a view the route does not offer.
+func requestedView(r *http.Request, cfg *routeConfig) (string, error) {
+	view := r.URL.Query().Get(viewQueryParam)
+	if view == "" || len(cfg.selectableViews) == 0 {
+		return cfg.view, nil
+	}
+
+	if view != cfg.view && !slices.Contains(cfg.selectableViews, view) {
+		return "", fmt.Errorf("view %q cannot be selected", view)
+	}
+
+	return view, nil
+}
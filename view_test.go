@@ -0,0 +1,58 @@
+package bodyrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testArticle struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title" view:"summary,detail"`
+	Body    string `json:"body" view:"detail"`
+	Authors []struct {
+		Name  string `json:"name"`
+		Email string `json:"email" view:"detail"`
+	} `json:"authors"`
+}
+
+func TestWithView(t *testing.T) {
+	article := testArticle{ID: 1, Title: "Hello", Body: "World"}
+	article.Authors = append(article.Authors, struct {
+		Name  string `json:"name"`
+		Email string `json:"email" view:"detail"`
+	}{Name: "john", Email: "john@example.com"})
+
+	getArticle := func() (testArticle, error) { return article, nil }
+
+	testCases := []struct {
+		name           string
+		opts           []Option
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "no view", url: "/articles/1", expectedStatus: http.StatusOK, expectedBody: `{"id":1,"title":"Hello","body":"World","authors":[{"name":"john","email":"john@example.com"}]}`},
+		{name: "route view", opts: []Option{WithView("summary")}, url: "/articles", expectedStatus: http.StatusOK, expectedBody: `{"id":1,"title":"Hello","authors":[{"name":"john"}]}`},
+		{name: "selected view", opts: []Option{WithView("summary", "detail")}, url: "/articles?view=detail", expectedStatus: http.StatusOK, expectedBody: `{"id":1,"title":"Hello","body":"World","authors":[{"name":"john","email":"john@example.com"}]}`},
+		{name: "view not selectable", opts: []Option{WithView("summary")}, url: "/articles?view=detail", expectedStatus: http.StatusOK, expectedBody: `{"id":1,"title":"Hello","authors":[{"name":"john"}]}`},
+		{name: "unknown view", opts: []Option{WithView("summary", "detail")}, url: "/articles?view=full", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			w := httptest.NewRecorder()
+			HandleTo(getArticle, tc.opts...).ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedBody != "" && strings.Join(strings.Fields(w.Body.String()), "") != tc.expectedBody {
+				t.Errorf("Expected body %s, got %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package bodyrest
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+)
+
+// Upgrader upgrades a request to a websocket connection. It is usually a
+// small adapter around the websocket library in use; the returned connection
+// is passed to the handler and closed when the handler returns.
+type Upgrader interface {
+	Upgrade(w http.ResponseWriter, r *http.Request) (io.Closer, error)
+}
+
+// UpgraderFunc adapts a function to the Upgrader interface.
+type UpgraderFunc func(w http.ResponseWriter, r *http.Request) (io.Closer, error)
+
+func (f UpgraderFunc) Upgrade(w http.ResponseWriter, r *http.Request) (io.Closer, error) {
+	return f(w, r)
+}
+
+// HandleWebSocket binds the request like HandleTo, upgrades the connection
+// with upgrader and hands it to the handler as its last parameter, e.g.
+// func(params T, conn *websocket.Conn) error. Binding errors go through the
+// rest error handler before the upgrade happens.
+func HandleWebSocket(upgrader Upgrader, handlerFunc interface{}, opts ...Option) http.HandlerFunc {
+	handlerType := reflect.TypeOf(handlerFunc)
+	if handlerType.Kind() != reflect.Func {
+		log.Fatal("Handler is not a function")
+	}
+
+	if handlerType.NumIn() == 0 {
+		log.Fatal("websocket handler must take the connection as its last parameter")
+	}
+
+	if handlerType.NumOut() != 1 || handlerType.Out(0) != errorType {
+		log.Fatal("websocket handler must return exactly one error value")
+	}
+
+	connType := handlerType.In(handlerType.NumIn() - 1)
+	cfg := newRouteConfig(opts)
+	plan := newBindPlan(handlerType, handlerType.NumIn()-1)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer recoverHandler(w, r)
+
+		finish, ok := admitRequest(w, r)
+		if !ok {
+			return
+		}
+		defer finish()
+
+		ctx, stop := withDrainSignal(r.Context())
+		defer stop()
+		r = r.WithContext(ctx)
+
+		handlerArgsToCall, ok := bindArgs(w, r, cfg, plan)
+		if !ok {
+			return
+		}
+		defer releaseArgs(handlerArgsToCall)
+
+		conn, err := upgrader.Upgrade(w, r)
+		if err != nil {
+			log.Printf("failed to upgrade websocket connection: %v\n", err)
+			return
+		}
+		defer conn.Close()
+
+		connValue := reflect.ValueOf(conn)
+		if !connValue.Type().AssignableTo(connType) {
+			log.Printf("upgraded connection %s is not assignable to %s\n", connValue.Type(), connType)
+			return
+		}
+
+		handlerArgsToCall = append(handlerArgsToCall, connValue)
+		results := reflect.ValueOf(handlerFunc).Call(handlerArgsToCall)
+
+		if err, _ := results[0].Interface().(error); err != nil {
+			log.Printf("websocket handler returned error: %v\n", err)
+		}
+	})
+}
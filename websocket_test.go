@@ -0,0 +1,92 @@
+package bodyrest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testConn struct {
+	closed bool
+}
+
+func (c *testConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type testRoomParams struct {
+	Room  string `path:"room"`
+	Token string `query:"token"`
+	Limit int    `query:"limit"`
+}
+
+func TestHandleWebSocket(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedParams testRoomParams
+		expectUpgrade  bool
+	}{
+		{
+			name:           "Binds path and query params",
+			path:           "/rooms/lobby?token=abc&limit=10",
+			expectedStatus: http.StatusSwitchingProtocols,
+			expectedParams: testRoomParams{Room: "lobby", Token: "abc", Limit: 10},
+			expectUpgrade:  true,
+		},
+		{
+			name:           "Invalid query param",
+			path:           "/rooms/lobby?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := &testConn{}
+			upgraded := false
+			upgrader := UpgraderFunc(func(w http.ResponseWriter, r *http.Request) (io.Closer, error) {
+				upgraded = true
+				w.WriteHeader(http.StatusSwitchingProtocols)
+				return conn, nil
+			})
+
+			var got testRoomParams
+			handler := func(params testRoomParams, c *testConn) error {
+				got = params
+				return nil
+			}
+
+			req, err := http.NewRequest("GET", tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/rooms/{room}", HandleWebSocket(upgrader, handler))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatus, w.Code)
+			}
+
+			if upgraded != tc.expectUpgrade {
+				t.Errorf("Expected upgrade %v, got %v", tc.expectUpgrade, upgraded)
+			}
+
+			if got != tc.expectedParams {
+				t.Errorf("Expected params %+v, got %+v", tc.expectedParams, got)
+			}
+
+			if tc.expectUpgrade && !conn.closed {
+				t.Error("Expected connection to be closed")
+			}
+		})
+	}
+}